@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"src/app/services"
+	"src/config"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+type CachePrune struct {
+	MaxSize         int  `short:"s" flag:"max-size" description:"Maximum size of the cache in MB, defaults to CONFETTI_CACHE_MAX_SIZE or 2048"`
+	All             bool `short:"a" flag:"all" description:"Remove everything from the cache"`
+	Verbose         bool `short:"v" description:"Show events"`
+	VeryVerbose     bool `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool `short:"vvv" description:"Show all events"`
+}
+
+func (p CachePrune) Name() string {
+	return "cache:prune"
+}
+
+func (p CachePrune) Description() string {
+	return "Removes the least recently used files from the cache (e.g. vendor directories)."
+}
+
+func (p CachePrune) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = p.Verbose || p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVerbose = p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVeryVerbose = p.VeryVeryVerbose
+
+	if config.App.Verbose {
+		c.Info("Use cache directory: %s", config.Cache.Dir)
+	}
+	fmt.Println("\n\033[34mConfetti cache:prune\n\033[0m") // blue
+
+	maxSize := config.Cache.MaxSize * 1024 * 1024
+	if p.MaxSize > 0 {
+		maxSize = int64(p.MaxSize) * 1024 * 1024
+	}
+	if p.All {
+		maxSize = 0
+	}
+
+	removed, err := services.PruneCache(maxSize)
+	for _, entry := range removed {
+		if config.App.Verbose {
+			c.Line("Removed %s (%s, last used %s)", entry.Path, formatMegabytes(entry.Size), entry.LastUsed.Format("2006-01-02 15:04"))
+		}
+	}
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+
+	entries, err := services.GetCacheEntries()
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	var freed, size int64
+	for _, entry := range removed {
+		freed += entry.Size
+	}
+	for _, entry := range entries {
+		size += entry.Size
+	}
+	c.Info("Removed %d files (%s), the cache is now %s", len(removed), formatMegabytes(freed), formatMegabytes(size))
+
+	return inter.Success
+}
+
+func formatMegabytes(bytes int64) string {
+	return fmt.Sprintf("%.1f MB", float64(bytes)/1024/1024)
+}
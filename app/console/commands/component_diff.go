@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"src/app/services"
+	"src/config"
+
+	"github.com/confetti-framework/framework/inter"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type ComponentDiff struct {
+	Directory       string `short:"d" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Environment     string `short:"e" flag:"environment" description:"The environment name in the config.json5 file"`
+	Component       string `short:"c" flag:"component" description:"Only compare this component, see component:list for the names"`
+	Verbose         bool   `short:"v" description:"Show events"`
+	VeryVerbose     bool   `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
+}
+
+func (d ComponentDiff) Name() string {
+	return "component:diff"
+}
+
+func (d ComponentDiff) Description() string {
+	return "Shows how the parsed schema of the components changed since the remote commit."
+}
+
+func (d ComponentDiff) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = d.Verbose || d.VeryVerbose || d.VeryVeryVerbose
+	config.App.VeryVerbose = d.VeryVerbose || d.VeryVeryVerbose
+	config.App.VeryVeryVerbose = d.VeryVeryVerbose
+	fmt.Println("\n\033[34mConfetti component:diff\n\033[0m") // blue
+	env, repo, err := componentParser(c, d.Directory, d.Environment)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+
+	// The parser knows the components at the remote commit, and with the local changes
+	remoteCommit := services.GetGitRemoteCommit()
+	if remoteCommit == "" {
+		c.Error("The project has no remote commit to compare with, push the project first")
+		return inter.Failure
+	}
+	if config.App.Verbose {
+		c.Info("Compare with remote commit %s", remoteCommit)
+	}
+	var changes []services.ComponentChange
+	if d.Component != "" {
+		// A component that is added or removed since the remote commit is not found on one side
+		before, err := componentOrNothing(services.GetParsedComponent(c, env, repo, d.Component, remoteCommit))
+		if err != nil {
+			c.Error(err.Error())
+			return inter.Failure
+		}
+		after, err := componentOrNothing(services.GetParsedComponent(c, env, repo, d.Component, ""))
+		if err != nil {
+			c.Error(err.Error())
+			return inter.Failure
+		}
+		if len(before) == 0 && len(after) == 0 {
+			c.Error("Component %s is not found, see component:list for the names", d.Component)
+			return inter.Failure
+		}
+		changes = services.DiffParsedComponents(before, after)
+	} else {
+		before, err := services.GetParsedComponents(c, env, repo, remoteCommit)
+		if err != nil {
+			c.Error(err.Error())
+			return inter.Failure
+		}
+		after, err := services.GetParsedComponents(c, env, repo, "")
+		if err != nil {
+			c.Error(err.Error())
+			return inter.Failure
+		}
+		changes = services.DiffParsedComponents(before, after)
+	}
+
+	if len(changes) == 0 {
+		c.Info("The schema of the components is not changed since the remote commit")
+		return inter.Success
+	}
+	ta := c.Table()
+	ta.AppendHeader(table.Row{"Component", "Field", "Change", "Before", "After"})
+	for _, change := range changes {
+		ta.AppendRow(table.Row{
+			fmt.Sprintf("\033[34m%s\033[0m", change.Component), // blue
+			change.Field,
+			componentChangeColor(change.Kind) + change.Kind + "\033[0m",
+			change.Before,
+			change.After,
+		})
+	}
+	ta.Render()
+	return inter.Success
+}
+
+// componentOrNothing returns no components when the parser doesn't know the component.
+func componentOrNothing(component services.ParsedComponent, err error) ([]services.ParsedComponent, error) {
+	if errors.Is(err, services.NotFoundError) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []services.ParsedComponent{component}, nil
+}
+
+func componentChangeColor(kind string) string {
+	switch kind {
+	case services.ComponentAdded:
+		return "\033[32m" // green
+	case services.ComponentRemoved:
+		return "\033[31m" // red
+	default:
+		return "\033[33m" // yellow
+	}
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"fmt"
+	"src/app/services"
+	"src/config"
+	"strings"
+
+	"github.com/confetti-framework/framework/inter"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type ComponentList struct {
+	Directory       string `short:"d" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Environment     string `short:"e" flag:"environment" description:"The environment name in the config.json5 file"`
+	Verbose         bool   `short:"v" description:"Show events"`
+	VeryVerbose     bool   `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
+}
+
+func (l ComponentList) Name() string {
+	return "component:list"
+}
+
+func (l ComponentList) Description() string {
+	return "Lists the components as the parser understands them, with the number of fields and errors."
+}
+
+func (l ComponentList) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = l.Verbose || l.VeryVerbose || l.VeryVeryVerbose
+	config.App.VeryVerbose = l.VeryVerbose || l.VeryVeryVerbose
+	config.App.VeryVeryVerbose = l.VeryVeryVerbose
+	fmt.Println("\n\033[34mConfetti component:list\n\033[0m") // blue
+	env, repo, err := componentParser(c, l.Directory, l.Environment)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+
+	components, err := services.GetParsedComponents(c, env, repo, "")
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	if len(components) == 0 {
+		c.Info("No components are parsed yet, start `conf watch` to parse them")
+		return inter.Success
+	}
+
+	ta := c.Table()
+	ta.AppendHeader(table.Row{"Component", "File", "Extends", "Fields", "Errors"})
+	for _, component := range components {
+		ta.AppendRow(table.Row{
+			fmt.Sprintf("\033[34m%s\033[0m", component.Name), // blue
+			component.File,
+			strings.Join(component.Extends, " → "),
+			len(component.Fields),
+			componentErrorCount(component),
+		})
+	}
+	ta.Render()
+	return inter.Success
+}
+
+// componentParser returns the environment and the repository to ask the parser about.
+func componentParser(c inter.Cli, directory, environment string) (services.Environment, string, error) {
+	root, err := getDirectoryOrCurrent(directory)
+	if err != nil {
+		return services.Environment{}, "", err
+	}
+	config.Path.Root = root
+	if config.App.Verbose {
+		c.Info("Use directory: %s", root)
+	}
+	env, err := services.GetEnvironmentByInput(c, environment)
+	if err != nil {
+		return services.Environment{}, "", fmt.Errorf("error getting environment: %w", err)
+	}
+	repo, err := services.GetRepositoryName(root)
+	if err != nil {
+		return services.Environment{}, "", err
+	}
+	return env, repo, nil
+}
+
+func componentErrorCount(component services.ParsedComponent) string {
+	if len(component.Errors) == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("\033[31m%d\033[0m", len(component.Errors)) // red
+}
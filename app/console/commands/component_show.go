@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"fmt"
+	"src/app/services"
+	"src/config"
+	"strings"
+
+	"github.com/confetti-framework/framework/inter"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type ComponentShow struct {
+	Directory       string `short:"d" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Environment     string `short:"e" flag:"environment" description:"The environment name in the config.json5 file"`
+	Component       string `short:"c" flag:"component" description:"The name of the component, see component:list"`
+	Verbose         bool   `short:"v" description:"Show events"`
+	VeryVerbose     bool   `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
+}
+
+func (s ComponentShow) Name() string {
+	return "component:show"
+}
+
+func (s ComponentShow) Description() string {
+	return "Shows the fields, the extends chain and the errors of a parsed component."
+}
+
+func (s ComponentShow) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = s.Verbose || s.VeryVerbose || s.VeryVeryVerbose
+	config.App.VeryVerbose = s.VeryVerbose || s.VeryVeryVerbose
+	config.App.VeryVeryVerbose = s.VeryVeryVerbose
+	fmt.Println("\n\033[34mConfetti component:show\n\033[0m") // blue
+	if s.Component == "" {
+		c.Error("-c or --component flag is required, see component:list for the names")
+		return inter.Failure
+	}
+	env, repo, err := componentParser(c, s.Directory, s.Environment)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+
+	component, err := services.GetParsedComponent(c, env, repo, s.Component, "")
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+
+	c.Line("Component: \033[34m%s\033[0m", component.Name)
+	c.Line("File:      %s", component.File)
+	if len(component.Extends) > 0 {
+		c.Line("Extends:   %s", strings.Join(component.Extends, " → "))
+	}
+	c.Line("")
+
+	if len(component.Fields) == 0 {
+		c.Line("The component has no fields")
+	} else {
+		ta := c.Table()
+		ta.AppendHeader(table.Row{"Field", "Component", "Decorations"})
+		for _, field := range component.Fields {
+			ta.AppendRow(table.Row{
+				fmt.Sprintf("\033[34m%s\033[0m", field.Key), // blue
+				field.Component,
+				field.DecorationsString(),
+			})
+		}
+		ta.Render()
+	}
+
+	if len(component.Errors) > 0 {
+		c.Line("")
+		c.Error("The parser found %d errors:", len(component.Errors))
+		for _, e := range component.Errors {
+			location := e.File
+			if e.Line > 0 {
+				location = fmt.Sprintf("%s:%d", e.File, e.Line)
+			}
+			c.Line("  %s \033[31m%s\033[0m", location, e.Message)
+		}
+		return inter.Failure
+	}
+	return inter.Success
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"src/app/services"
+	"src/config"
+	"strings"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+type ConfigShow struct {
+	Directory       string `short:"d" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Environment     string `short:"e" flag:"env" description:"Only show this environment, e.g. dev"`
+	Verbose         bool   `short:"v" description:"Show events"`
+	VeryVerbose     bool   `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
+}
+
+func (s ConfigShow) Name() string {
+	return "config:show"
+}
+
+func (s ConfigShow) Description() string {
+	return "Shows config.json5 with config.local.json5, extends and variables resolved."
+}
+
+func (s ConfigShow) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = s.Verbose || s.VeryVerbose || s.VeryVeryVerbose
+	config.App.VeryVerbose = s.VeryVerbose || s.VeryVeryVerbose
+	config.App.VeryVeryVerbose = s.VeryVeryVerbose
+
+	root, err := getDirectoryOrCurrent(s.Directory)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	config.Path.Root = root
+
+	if config.App.Verbose {
+		c.Info("Use directory: %s", root)
+	}
+
+	resolved, err := services.GetResolvedAppConfig()
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+
+	var result any = resolved
+	if s.Environment != "" {
+		result, err = findResolvedEnvironment(resolved, s.Environment)
+		if err != nil {
+			c.Error(err.Error())
+			return inter.Failure
+		}
+	}
+
+	content, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	fmt.Println(string(content))
+	return inter.Success
+}
+
+func findResolvedEnvironment(resolved map[string]any, name string) (any, error) {
+	environments, _ := resolved["environments"].([]any)
+	names := []string{}
+	for _, environment := range environments {
+		env, ok := environment.(map[string]any)
+		if !ok {
+			continue
+		}
+		if env["name"] == name {
+			return env, nil
+		}
+		names = append(names, fmt.Sprint(env["name"]))
+	}
+	return nil, fmt.Errorf("the name %s does not match any environment. Available names are %s", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/app/services"
+	"src/config"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+type ConfigValidate struct {
+	Directory       string `short:"d" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Schema          bool   `short:"s" flag:"schema" description:"Print the JSON schema of config.json5, e.g. to use in your editor"`
+	Verbose         bool   `short:"v" description:"Show events"`
+	VeryVerbose     bool   `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
+}
+
+func (v ConfigValidate) Name() string {
+	return "config:validate"
+}
+
+func (v ConfigValidate) Description() string {
+	return "Validates config.json5 (and config.local.json5) and shows where the errors are."
+}
+
+func (v ConfigValidate) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = v.Verbose || v.VeryVerbose || v.VeryVeryVerbose
+	config.App.VeryVerbose = v.VeryVerbose || v.VeryVeryVerbose
+	config.App.VeryVeryVerbose = v.VeryVeryVerbose
+
+	if v.Schema {
+		fmt.Println(string(config.Embed.ConfigSchema))
+		return inter.Success
+	}
+
+	root, err := getDirectoryOrCurrent(v.Directory)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	config.Path.Root = root
+
+	if config.App.Verbose {
+		c.Info("Use directory: %s", root)
+	}
+	fmt.Println("\n\033[34mConfetti config:validate\n\033[0m") // blue
+
+	file := filepath.Join(config.Path.Root, "config.json5")
+	content, err := os.ReadFile(file)
+	if err != nil {
+		c.Error("Probably, you are not running this command in a Confetti project. Error: %s", err)
+		return inter.Failure
+	}
+
+	localFile := filepath.Join(config.Path.Root, "config.local.json5")
+	localContent, err := os.ReadFile(localFile)
+	if err != nil && !os.IsNotExist(err) {
+		c.Error("Unable to read %s: %s", localFile, err)
+		return inter.Failure
+	}
+	if err == nil {
+		file = file + " (with " + filepath.Base(localFile) + ")"
+		if !services.GitIgnored(localFile) {
+			c.Comment("%s is not ignored by git, add it to .gitignore to keep your local settings out of the repository", localFile)
+		}
+	}
+
+	errs := services.ValidateAppConfig(content, localContent)
+	if len(errs) == 0 {
+		c.Info("%s is valid", file)
+		return inter.Success
+	}
+	for _, err := range errs {
+		c.Error(err.Error())
+	}
+	if len(errs) == 1 {
+		c.Line("\nFound 1 error in %s", file)
+	} else {
+		c.Line("\nFound %d errors in %s", len(errs), file)
+	}
+	return inter.Failure
+}
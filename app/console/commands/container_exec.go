@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"src/app/services"
+	"src/app/services/orchestrator"
+	"src/config"
+	"strings"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+type ContainerExec struct {
+	Directory         string   `short:"d" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Environment       string   `short:"e" flag:"environment" description:"The environment name in the config.json5 file"`
+	Organisation      string   `short:"o" flag:"organisation" description:"The organisation name (e.g. agency-name is the organisation when repository is: 'agency-name/website-name')"`
+	Repository        string   `short:"r" flag:"repository" description:"The repository name (e.g. website-name is the repository when repository is: 'agency-name/website-name')"`
+	Locator           []string `flag:"locator" description:"The locator of the container, e.g. agency-name/website-name/website?env=dev"`
+	ContainerName     []string `flag:"name" description:"The name of the container, e.g. --name=website"`
+	AuthEnvironment   string   `flag:"auth-environment" description:"The environment in config.json5 to authorize with, when --environment is * or not in config.json5"`
+	NoInteraction     bool     `short:"n" flag:"no-interaction" description:"Fail instead of asking questions, e.g. in scripts"`
+	Command           string   `short:"c" flag:"command" description:"The command to run with sh -c, or add the command after --, e.g. conf container:exec --name=website -- composer install"`
+	ConfirmProduction bool     `flag:"confirm-production" description:"Required to run a command in a production container"`
+	Verbose           bool     `short:"v" description:"Show events"`
+	VeryVerbose       bool     `short:"vv" description:"Show more events"`
+	VeryVeryVerbose   bool     `short:"vvv" description:"Show all events"`
+}
+
+func (e ContainerExec) Name() string {
+	return "container:exec"
+}
+
+func (e ContainerExec) Description() string {
+	return "Run a command in a container, e.g. conf container:exec --name=website -- php -v"
+}
+
+func (e ContainerExec) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = e.Verbose || e.VeryVerbose || e.VeryVeryVerbose
+	config.App.VeryVerbose = e.VeryVerbose || e.VeryVeryVerbose
+	config.App.VeryVeryVerbose = e.VeryVeryVerbose
+	root, err := getDirectoryOrCurrent(e.Directory)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	config.Path.Root = root
+
+	if config.App.Verbose {
+		c.Info("Use directory: %s", root)
+	}
+
+	command := argsAfterDoubleDash(config.App.OsArgs)
+	if e.Command != "" {
+		command = []string{"sh", "-c", e.Command}
+	}
+	if len(command) == 0 {
+		c.Error("No command given, add the command after -- (e.g. conf container:exec --name=website -- php -v) or use --command")
+		return inter.Failure
+	}
+
+	selection := containerSelection{
+		Environment:     e.Environment,
+		AuthEnvironment: e.AuthEnvironment,
+		Organisation:    e.Organisation,
+		Repository:      e.Repository,
+		Filter:          services.ContainerFilter{Names: e.ContainerName, Locators: e.Locator},
+		NoInteraction:   e.NoInteraction,
+	}
+	runningEnv, containers, err := selection.selectContainers(c)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	container, err := chooseOneContainer(c, containers, selection.interactive())
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+
+	if container.Environment.Stage == "production" && !e.ConfirmProduction {
+		c.Error("%s runs in production, add --confirm-production to run `%s` in it", container.Locator, strings.Join(command, " "))
+		return inter.Failure
+	}
+	if config.App.Verbose {
+		c.Info("Run `%s` in %s", strings.Join(command, " "), container.Locator)
+	}
+
+	exitCode, err := services.NewOrchestratorClient(c, runningEnv).Exec(container.ID, command, os.Stdout, os.Stderr)
+	if err != nil {
+		c.Error("Unable to run the command in %s: %s", container.Locator, err)
+		return inter.Failure
+	}
+	return inter.ExitCode(exitCode)
+}
+
+// chooseOneContainer asks which container to use when the selection matches multiple containers.
+func chooseOneContainer(c inter.Cli, containers []orchestrator.ContainerInformation, interactive bool) (orchestrator.ContainerInformation, error) {
+	if len(containers) == 0 {
+		return orchestrator.ContainerInformation{}, fmt.Errorf("no container matches the selection")
+	}
+	if len(containers) == 1 {
+		return containers[0], nil
+	}
+	locators := []string{}
+	for _, container := range containers {
+		locators = append(locators, container.Locator)
+	}
+	if !interactive {
+		return orchestrator.ContainerInformation{}, fmt.Errorf("multiple containers found, select one with --locator: %s", strings.Join(locators, ", "))
+	}
+	selected := c.Choice("Select a container:", locators...)
+	for _, container := range containers {
+		if container.Locator == selected {
+			return container, nil
+		}
+	}
+	return orchestrator.ContainerInformation{}, fmt.Errorf("no container found with locator %s", selected)
+}
+
+// argsAfterDoubleDash returns the arguments after --, these are not parsed as flags.
+func argsAfterDoubleDash(args []string) []string {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[i+1:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"fmt"
+	"src/app/services"
+	"src/app/services/orchestrator"
+	"src/config"
+	"sync"
+	"time"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+type ContainerStart struct {
+	Directory         string        `short:"d" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Environment       string        `short:"e" flag:"environment" description:"The environment name in the config.json5 file"`
+	Organisation      string        `short:"o" flag:"organisation" description:"The organisation name (e.g. agency-name is the organisation when repository is: 'agency-name/website-name')"`
+	Repository        string        `short:"r" flag:"repository" description:"The repository name (e.g. website-name is the repository when repository is: 'agency-name/website-name')"`
+	Locator           []string      `flag:"locator" description:"The locator of the container, e.g. agency-name/website-name/website?env=dev"`
+	ContainerName     []string      `flag:"name" description:"The name of the containers, e.g. --name=website"`
+	AuthEnvironment   string        `flag:"auth-environment" description:"The environment in config.json5 to authorize with, when --environment is * or not in config.json5"`
+	NoInteraction     bool          `short:"n" flag:"no-interaction" description:"Fail instead of asking questions, e.g. in scripts"`
+	Timeout           time.Duration `short:"t" flag:"timeout" description:"How long to wait until the containers are ready, e.g. 2m (default 1m)"`
+	ConfirmProduction bool          `flag:"confirm-production" description:"Required to start production containers"`
+	Verbose           bool          `short:"v" description:"Show events"`
+	VeryVerbose       bool          `short:"vv" description:"Show more events"`
+	VeryVeryVerbose   bool          `short:"vvv" description:"Show all events"`
+}
+
+func (s ContainerStart) Name() string {
+	return "container:start"
+}
+
+func (s ContainerStart) Description() string {
+	return "Start containers and wait until they are running."
+}
+
+func (s ContainerStart) Handle(c inter.Cli) inter.ExitCode {
+	return containerLifecycle{
+		action:    "start",
+		directory: s.Directory,
+		selection: containerSelection{
+			Environment:     s.Environment,
+			AuthEnvironment: s.AuthEnvironment,
+			Organisation:    s.Organisation,
+			Repository:      s.Repository,
+			Filter:          services.ContainerFilter{Names: s.ContainerName, Locators: s.Locator},
+			NoInteraction:   s.NoInteraction,
+		},
+		timeout:           s.Timeout,
+		confirmProduction: s.ConfirmProduction,
+		verbose:           s.Verbose,
+		veryVerbose:       s.VeryVerbose,
+		veryVeryVerbose:   s.VeryVeryVerbose,
+	}.handle(c)
+}
+
+type ContainerStop struct {
+	Directory         string        `short:"d" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Environment       string        `short:"e" flag:"environment" description:"The environment name in the config.json5 file"`
+	Organisation      string        `short:"o" flag:"organisation" description:"The organisation name (e.g. agency-name is the organisation when repository is: 'agency-name/website-name')"`
+	Repository        string        `short:"r" flag:"repository" description:"The repository name (e.g. website-name is the repository when repository is: 'agency-name/website-name')"`
+	Locator           []string      `flag:"locator" description:"The locator of the container, e.g. agency-name/website-name/website?env=dev"`
+	ContainerName     []string      `flag:"name" description:"The name of the containers, e.g. --name=website"`
+	AuthEnvironment   string        `flag:"auth-environment" description:"The environment in config.json5 to authorize with, when --environment is * or not in config.json5"`
+	NoInteraction     bool          `short:"n" flag:"no-interaction" description:"Fail instead of asking questions, e.g. in scripts"`
+	Timeout           time.Duration `short:"t" flag:"timeout" description:"How long to wait until the containers are ready, e.g. 2m (default 1m)"`
+	ConfirmProduction bool          `flag:"confirm-production" description:"Required to stop production containers"`
+	Verbose           bool          `short:"v" description:"Show events"`
+	VeryVerbose       bool          `short:"vv" description:"Show more events"`
+	VeryVeryVerbose   bool          `short:"vvv" description:"Show all events"`
+}
+
+func (s ContainerStop) Name() string {
+	return "container:stop"
+}
+
+func (s ContainerStop) Description() string {
+	return "Stop containers and wait until they are stopped."
+}
+
+func (s ContainerStop) Handle(c inter.Cli) inter.ExitCode {
+	return containerLifecycle{
+		action:    "stop",
+		directory: s.Directory,
+		selection: containerSelection{
+			Environment:     s.Environment,
+			AuthEnvironment: s.AuthEnvironment,
+			Organisation:    s.Organisation,
+			Repository:      s.Repository,
+			Filter:          services.ContainerFilter{Names: s.ContainerName, Locators: s.Locator},
+			NoInteraction:   s.NoInteraction,
+		},
+		timeout:           s.Timeout,
+		confirmProduction: s.ConfirmProduction,
+		verbose:           s.Verbose,
+		veryVerbose:       s.VeryVerbose,
+		veryVeryVerbose:   s.VeryVeryVerbose,
+	}.handle(c)
+}
+
+type ContainerRestart struct {
+	Directory         string        `short:"d" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Environment       string        `short:"e" flag:"environment" description:"The environment name in the config.json5 file"`
+	Organisation      string        `short:"o" flag:"organisation" description:"The organisation name (e.g. agency-name is the organisation when repository is: 'agency-name/website-name')"`
+	Repository        string        `short:"r" flag:"repository" description:"The repository name (e.g. website-name is the repository when repository is: 'agency-name/website-name')"`
+	Locator           []string      `flag:"locator" description:"The locator of the container, e.g. agency-name/website-name/website?env=dev"`
+	ContainerName     []string      `flag:"name" description:"The name of the containers, e.g. --name=website"`
+	AuthEnvironment   string        `flag:"auth-environment" description:"The environment in config.json5 to authorize with, when --environment is * or not in config.json5"`
+	NoInteraction     bool          `short:"n" flag:"no-interaction" description:"Fail instead of asking questions, e.g. in scripts"`
+	Timeout           time.Duration `short:"t" flag:"timeout" description:"How long to wait until the containers are ready, e.g. 2m (default 1m)"`
+	ConfirmProduction bool          `flag:"confirm-production" description:"Required to restart production containers"`
+	Verbose           bool          `short:"v" description:"Show events"`
+	VeryVerbose       bool          `short:"vv" description:"Show more events"`
+	VeryVeryVerbose   bool          `short:"vvv" description:"Show all events"`
+}
+
+func (s ContainerRestart) Name() string {
+	return "container:restart"
+}
+
+func (s ContainerRestart) Description() string {
+	return "Restart containers and wait until they are running again."
+}
+
+func (s ContainerRestart) Handle(c inter.Cli) inter.ExitCode {
+	return containerLifecycle{
+		action:    "restart",
+		directory: s.Directory,
+		selection: containerSelection{
+			Environment:     s.Environment,
+			AuthEnvironment: s.AuthEnvironment,
+			Organisation:    s.Organisation,
+			Repository:      s.Repository,
+			Filter:          services.ContainerFilter{Names: s.ContainerName, Locators: s.Locator},
+			NoInteraction:   s.NoInteraction,
+		},
+		timeout:           s.Timeout,
+		confirmProduction: s.ConfirmProduction,
+		verbose:           s.Verbose,
+		veryVerbose:       s.VeryVerbose,
+		veryVeryVerbose:   s.VeryVeryVerbose,
+	}.handle(c)
+}
+
+// containerLifecycle holds the flags of container:start, container:stop and container:restart.
+type containerLifecycle struct {
+	action            string
+	directory         string
+	selection         containerSelection
+	timeout           time.Duration
+	confirmProduction bool
+	verbose           bool
+	veryVerbose       bool
+	veryVeryVerbose   bool
+}
+
+func (l containerLifecycle) handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = l.verbose || l.veryVerbose || l.veryVeryVerbose
+	config.App.VeryVerbose = l.veryVerbose || l.veryVeryVerbose
+	config.App.VeryVeryVerbose = l.veryVeryVerbose
+	root, err := getDirectoryOrCurrent(l.directory)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	config.Path.Root = root
+
+	if config.App.Verbose {
+		c.Info("Use directory: %s", root)
+	}
+	fmt.Printf("\n\033[34mConfetti container:%s\n\033[0m\n", l.action) // blue
+
+	timeout := l.timeout
+	if timeout == 0 {
+		timeout = time.Minute
+	}
+
+	runningEnv, containers, err := l.selection.selectContainers(c)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	if len(containers) == 0 {
+		c.Error("No container matches the selection")
+		return inter.Failure
+	}
+	for _, container := range containers {
+		if container.Environment.Stage == "production" && !l.confirmProduction {
+			c.Error("%s runs in production, add --confirm-production to %s it", container.Locator, l.action)
+			return inter.Failure
+		}
+	}
+
+	client := services.NewOrchestratorClient(c, runningEnv)
+	ready := []string{"running"}
+	if l.action == "stop" {
+		ready = []string{"stopped", "exited"}
+	}
+
+	var mu sync.Mutex
+	failed := false
+	var wg sync.WaitGroup
+	for _, container := range containers {
+		wg.Add(1)
+		go func(container orchestrator.ContainerInformation) {
+			defer wg.Done()
+			report := func(format string, args ...any) {
+				mu.Lock()
+				defer mu.Unlock()
+				c.Line("%s: %s", container.Locator, fmt.Sprintf(format, args...))
+			}
+			var err error
+			switch l.action {
+			case "start":
+				err = client.StartContainer(container.ID)
+			case "stop":
+				err = client.StopContainer(container.ID)
+			case "restart":
+				err = client.RestartContainer(container.ID)
+			}
+			status := ""
+			if err == nil {
+				var result orchestrator.ContainerInformation
+				onChange := func(status string) {
+					report("%s", status)
+				}
+				if l.action == "restart" {
+					result, err = client.WaitForRestart(container, timeout, time.Second, onChange)
+				} else {
+					result, err = client.WaitForStatus(container.ID, ready, timeout, time.Second, onChange)
+				}
+				status = result.Status
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = true
+				c.Error("Unable to %s %s: %s", l.action, container.Locator, err)
+				return
+			}
+			c.Info("%s is %s", container.Locator, status)
+		}(container)
+	}
+	wg.Wait()
+
+	if failed {
+		return inter.Failure
+	}
+	return inter.Success
+}
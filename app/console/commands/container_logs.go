@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"src/app/services"
+	"src/app/services/orchestrator"
+	"src/config"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+type ContainerLogs struct {
+	Directory       string   `short:"d" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Environment     string   `short:"e" flag:"environment" description:"The environment name in the config.json5 file"`
+	Organisation    string   `short:"o" flag:"organisation" description:"The organisation name (e.g. agency-name is the organisation when repository is: 'agency-name/website-name')"`
+	Repository      string   `short:"r" flag:"repository" description:"The repository name (e.g. website-name is the repository when repository is: 'agency-name/website-name')"`
+	Locator         []string `flag:"locator" description:"The locator of the container, e.g. agency-name/website-name/website?env=dev"`
+	ContainerName   []string `flag:"name" description:"Only show the logs of containers with this name, e.g. --name=website"`
+	AuthEnvironment string   `flag:"auth-environment" description:"The environment in config.json5 to authorize with, when --environment is * or not in config.json5"`
+	NoInteraction   bool     `short:"n" flag:"no-interaction" description:"Fail instead of asking questions, e.g. in scripts"`
+	Follow          bool     `short:"f" flag:"follow" description:"Keep streaming new log lines"`
+	Since           string   `flag:"since" description:"Only show lines since a duration (e.g. 10m) or a timestamp (e.g. 2024-01-02T15:04:05Z)"`
+	Tail            int      `flag:"tail" description:"Number of lines to show from the end of the logs"`
+	Grep            string   `flag:"grep" description:"Only show lines that match this regular expression"`
+	Verbose         bool     `short:"v" description:"Show events"`
+	VeryVerbose     bool     `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool     `short:"vvv" description:"Show all events"`
+}
+
+func (l ContainerLogs) Name() string {
+	return "container:logs"
+}
+
+func (l ContainerLogs) Description() string {
+	return "Show (and follow) the logs of one or more containers."
+}
+
+var logColors = []string{"\033[36m", "\033[33m", "\033[35m", "\033[32m", "\033[34m", "\033[91m"}
+
+func (l ContainerLogs) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = l.Verbose || l.VeryVerbose || l.VeryVeryVerbose
+	config.App.VeryVerbose = l.VeryVerbose || l.VeryVeryVerbose
+	config.App.VeryVeryVerbose = l.VeryVeryVerbose
+	root, err := getDirectoryOrCurrent(l.Directory)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	config.Path.Root = root
+
+	if config.App.Verbose {
+		c.Info("Use directory: %s", root)
+	}
+
+	since, err := parseSince(l.Since, time.Now())
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	var grep *regexp.Regexp
+	if l.Grep != "" {
+		grep, err = regexp.Compile(l.Grep)
+		if err != nil {
+			c.Error("Invalid --grep: %s", err)
+			return inter.Failure
+		}
+	}
+
+	selection := containerSelection{
+		Environment:     l.Environment,
+		AuthEnvironment: l.AuthEnvironment,
+		Organisation:    l.Organisation,
+		Repository:      l.Repository,
+		Filter:          services.ContainerFilter{Names: l.ContainerName, Locators: l.Locator},
+		NoInteraction:   l.NoInteraction,
+	}
+	runningEnv, containers, err := selection.selectContainers(c)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	client := services.NewOrchestratorClient(c, runningEnv)
+
+	// Align the log lines of all containers
+	labels := map[string]string{}
+	width := 0
+	for _, container := range containers {
+		label := container.Environment.Name + "/" + container.Name
+		labels[container.ID] = label
+		width = max(width, len(label))
+	}
+
+	var mu sync.Mutex
+	failed := false
+	var wg sync.WaitGroup
+	for n, container := range containers {
+		wg.Add(1)
+		go func(container orchestrator.ContainerInformation, color string) {
+			defer wg.Done()
+			prefix := fmt.Sprintf("%s%-*s |\033[0m ", color, width, labels[container.ID])
+			if len(containers) == 1 {
+				prefix = ""
+			}
+			handle := func(line orchestrator.LogLine) error {
+				if grep != nil && !grep.MatchString(line.Message) {
+					return nil
+				}
+				message := strings.TrimRight(line.Message, "\n")
+				if line.Stream == "stderr" {
+					message = "\033[31m" + message + "\033[0m"
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				fmt.Println(prefix + message)
+				return nil
+			}
+			options := orchestrator.LogOptions{Since: since, Tail: l.Tail}
+			var err error
+			if l.Follow {
+				options.OnReconnect = func(err error, delay time.Duration) {
+					if config.App.Verbose && err != nil {
+						c.Comment("Logs of %s interrupted (%s), reconnecting in %s", labels[container.ID], err, delay)
+					} else if config.App.Verbose {
+						c.Comment("Logs of %s closed, reconnecting in %s", labels[container.ID], delay)
+					}
+				}
+				err = client.FollowLogs(container.ID, options, handle)
+			} else {
+				err = client.StreamLogs(container.ID, options, handle)
+			}
+			if err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				failed = true
+				c.Error("Unable to get the logs of %s: %s", labels[container.ID], err)
+			}
+		}(container, logColors[n%len(logColors)])
+	}
+	wg.Wait()
+
+	if failed {
+		return inter.Failure
+	}
+	return inter.Success
+}
+
+// parseSince accepts a duration before now (e.g. 10m) or a RFC 3339 timestamp.
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	duration, err := time.ParseDuration(since)
+	if err == nil {
+		return now.Add(-duration), nil
+	}
+	moment, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q, use a duration (e.g. 10m) or a timestamp (e.g. 2024-01-02T15:04:05Z)", since)
+	}
+	return moment, nil
+}
@@ -1,24 +1,35 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"src/app/services"
+	"src/app/services/orchestrator"
 	"src/config"
+	"strings"
 	"time"
 
 	"github.com/confetti-framework/framework/inter"
 	"github.com/jedib0t/go-pretty/v6/table"
+	"gopkg.in/yaml.v3"
 )
 
 type ContainerQuery struct {
-	Directory       string `short:"d" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
-	Environment     string `short:"e" flag:"environment" description:"The environment name in the config.json5 file"`
-	Organisation    string `short:"o" flag:"organisation" description:"The organisation name (e.g. agency-name is the organisation when repository is: 'agency-name/website-name')"`
-	Repository      string `short:"r" flag:"repository" description:"The repository name (e.g. website-name is the repository when repository is: 'agency-name/website-name')"`
-	Verbose         bool   `short:"v" description:"Show events"`
-	VeryVerbose     bool   `short:"vv" description:"Show more events"`
-	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
-	Local           bool   `short:"l" description:"Only used by Confetti developers to run against the local orchestrator"`
+	Directory       string        `short:"d" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Environment     string        `short:"e" flag:"environment" description:"The environment name in the config.json5 file"`
+	Organisation    string        `short:"o" flag:"organisation" description:"The organisation name (e.g. agency-name is the organisation when repository is: 'agency-name/website-name')"`
+	Repository      string        `short:"r" flag:"repository" description:"The repository name (e.g. website-name is the repository when repository is: 'agency-name/website-name')"`
+	Status          []string      `flag:"status" description:"Only show containers with this status, e.g. --status=running,paused"`
+	ContainerName   []string      `flag:"name" description:"Only show containers with this name, e.g. --name=website"`
+	Stage           []string      `flag:"stage" description:"Only show containers in this stage, e.g. --stage=development"`
+	Output          string        `flag:"output" description:"Output format: table (default), wide, json or yaml"`
+	AuthEnvironment string        `flag:"auth-environment" description:"The environment in config.json5 to authorize with, when --environment is * or not in config.json5"`
+	NoInteraction   bool          `short:"n" flag:"no-interaction" description:"Fail instead of asking questions, e.g. in scripts"`
+	Watch           bool          `short:"w" flag:"watch" description:"Keep the table up to date until you press Ctrl+C"`
+	Interval        time.Duration `flag:"interval" description:"How often to refresh the table with --watch, e.g. 5s (default 2s)"`
+	Verbose         bool          `short:"v" description:"Show events"`
+	VeryVerbose     bool          `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool          `short:"vvv" description:"Show all events"`
 }
 
 func (l ContainerQuery) Name() string {
@@ -33,7 +44,6 @@ func (l ContainerQuery) Handle(c inter.Cli) inter.ExitCode {
 	config.App.Verbose = l.Verbose || l.VeryVerbose || l.VeryVeryVerbose
 	config.App.VeryVerbose = l.VeryVerbose || l.VeryVeryVerbose
 	config.App.VeryVeryVerbose = l.VeryVeryVerbose
-	config.App.Local = l.Local
 	root, err := getDirectoryOrCurrent(l.Directory)
 	if err != nil {
 		c.Error(err.Error())
@@ -41,53 +51,72 @@ func (l ContainerQuery) Handle(c inter.Cli) inter.ExitCode {
 	}
 	config.Path.Root = root
 
+	output := l.Output
+	if output == "" {
+		output = "table"
+	}
+	if !services.ContainsString([]string{"table", "wide", "json", "yaml"}, output) {
+		c.Error("Unknown output %q, use table, wide, json or yaml", output)
+		return inter.Failure
+	}
+	if l.Watch && output != "table" && output != "wide" {
+		c.Error("--watch can only be used with --output=table or --output=wide")
+		return inter.Failure
+	}
+
 	if config.App.Verbose {
 		c.Info("Use directory: %s", root)
 	}
 
-	// Get Environment for querying containers
-	env := l.Environment
-	if env == "" {
-		fmt.Println("\n\033[34mConfetti container:log\n\033[0m")
-		env, err = GetEnvironmentName(c, l.Environment)
-		if err != nil {
-			c.Error(fmt.Sprintf("Error getting environment: %s", err))
-			return inter.Failure
-		}
+	if output == "table" || output == "wide" {
+		fmt.Println("\n\033[34mConfetti container:query\n\033[0m")
 	}
 
-	// Get the running environment, only to validate the user is authorized
-	runningEnv, err := services.GetEnvironmentByInput(c, env)
+	selection := containerSelection{
+		Environment:     l.Environment,
+		AuthEnvironment: l.AuthEnvironment,
+		Organisation:    l.Organisation,
+		Repository:      l.Repository,
+		Filter: services.ContainerFilter{
+			Statuses: l.Status,
+			Names:    l.ContainerName,
+			Stages:   l.Stage,
+		},
+		NoInteraction: l.NoInteraction,
+		// The dashboard clears the screen, so it shows the skipped containers itself
+		CollectSkipped: l.Watch,
+	}
+	runningEnv, containers, err := selection.selectContainers(c)
 	if err != nil {
-		// If we can't get the environment by the query env (e.g. "all environments"),
-		// We ask the user to select an running environment
-		fmt.Printf("We couldn't find the environment by the query.\nPlease select an environment so we can check if you are authorized.\n")
-		runningEnv, err = services.GetEnvironmentByInput(c, "")
-		if err != nil {
-			c.Error(fmt.Sprintf("Error getting environment: %s", err))
-			return inter.Failure
-		}
+		c.Error(err.Error())
+		return inter.Failure
 	}
 
-	// Get all basic information about the containers the containers
-	query := services.QueryContainerOptions{
-		Environment:          env,
-		UmbrellaOrganization: l.Organisation,
-		UmbrellaRepository:   l.Repository,
+	if l.Watch {
+		interval := l.Interval
+		if interval == 0 {
+			interval = 2 * time.Second
+		}
+		watchContainers(c, runningEnv, selection, containers, interval, output == "wide")
+		return inter.Success
 	}
-	containers, err := services.GetContainers(c, runningEnv, query)
-	// Get the organisation name for the query
-	if err != nil {
-		c.Error(fmt.Sprintf("Error getting containers: %s", err))
-		return inter.Failure
+
+	switch output {
+	case "json", "yaml":
+		err = printContainers(containers, output)
+		if err != nil {
+			c.Error(err.Error())
+			return inter.Failure
+		}
+		return inter.Success
 	}
-	containers, chosenOrg := FilterOrganisation(c, containers)
-	containers, chosenRepo := FilterRepository(c, containers)
 
-	fmt.Printf("\n\033[0mconf container:query --e=\"%s\" --o=\"%s\" --r=\"%s\"\n\033[0m", env, chosenOrg, chosenRepo)
-	renderContainerTable(c, containers)
+	fmt.Printf("\n\033[0mconf container:query --environment=\"%s\" --organisation=\"%s\" --repository=\"%s\"\n\033[0m", selection.Environment, selection.Organisation, selection.Repository)
+	renderContainerTable(c, containers, output == "wide")
 
-	printEasterEgg()
+	if selection.interactive() {
+		printEasterEgg()
+	}
 
 	// The watch is preventing the code from ever getting here
 	return inter.Success
@@ -216,132 +245,62 @@ func printCharacter(times int, char string, duration time.Duration) {
 	}
 }
 
-const AllEnvironments = "all environments"
-
-func GetEnvironmentName(c inter.Cli, envName string) (string, error) {
-	appConfig, err := services.GetAppConfig()
-	if err != nil {
-		return "", err
-	}
-	names := []string{AllEnvironments}
-	for _, environment := range appConfig.Environments {
-		names = append(names, environment.Name)
-	}
-	if len(names) == 1 {
-		return "", fmt.Errorf("no environments found, please define an environment in your config.json5 file")
-	}
-	envName = c.Choice("You can narrow down your search by selecting an environment:", names...)
-	if envName == AllEnvironments {
-		envName = "*"
+func renderContainerTable(c inter.Cli, containers []orchestrator.ContainerInformation, wide bool) {
+	ta := c.Table()
+	header := table.Row{"Environment", "Name", "target", "status"}
+	if wide {
+		header = append(header, "ports", "network", "locator", "id")
 	}
-
-	return envName, nil
-}
-
-const AllOrganisations = "all organisations"
-
-func FilterOrganisation(c inter.Cli, containers []services.ContainerInformation) ([]services.ContainerInformation, string) {
-	// Get all unique organisation names from the containers
-	orgMap := map[string]bool{}
+	ta.AppendHeader(header)
 	for _, container := range containers {
-		if container.UmbrellaOrganization == "" {
-			panic(fmt.Sprintf("Container %s has no umbrella organization set, this should not happen. Locator: %s", container.Name, container.Locator))
+		row := table.Row{
+			fmt.Sprintf("\033[34m%s\033[0m", container.Environment.Name), // blue
+			fmt.Sprintf("\033[34m%s\033[0m", container.Name),             // blue
+			fmt.Sprintf("\033[34m%s\033[0m", container.Target),           // magenta
+			fmt.Sprintf("%s%s\033[0m", statusColor(container.Status), container.Status),
 		}
-		orgMap[container.UmbrellaOrganization] = true
-	}
-	orgNames := []string{}
-	for orgName := range orgMap {
-		orgNames = append(orgNames, orgName)
-	}
-
-	// If there is only one organisation, we return all containers
-	if len(orgNames) == 1 {
-		return containers, orgNames[0]
-	}
-
-	// If there are no organisations, we return an empty slice
-	if len(orgNames) == 0 {
-		return []services.ContainerInformation{}, "*"
-	}
-
-	// Multiple organisations, prompt user
-	// If the user has specified an organisation, we already have the containers filtered
-	choices := append([]string{AllOrganisations}, orgNames...)
-	selected := c.Choice("Select an organisation:", choices...)
-	if selected == AllOrganisations {
-		return containers, "*"
-	}
-	filtered := []services.ContainerInformation{}
-	for _, container := range containers {
-		if container.UmbrellaOrganization == selected {
-			filtered = append(filtered, container)
+		if wide {
+			row = append(row, containerWideColumns(container)...)
 		}
+		ta.AppendRow(row)
 	}
-	return filtered, selected
+	ta.Render()
 }
 
-const AllRepositories = "all repositories"
-
-func FilterRepository(c inter.Cli, containers []services.ContainerInformation) ([]services.ContainerInformation, string) {
-	// Get all unique repository names from the containers
-	repoMap := map[string]bool{}
-	for _, container := range containers {
-		if container.UmbrellaRepository == "" {
-			panic(fmt.Sprintf("Container %s has no umbrella repository set, this should not happen. Locator: %s", container.Name, container.Locator))
-		}
-		repoMap[container.UmbrellaRepository] = true
-	}
-	repoNames := []string{}
-	for repoName := range repoMap {
-		repoNames = append(repoNames, repoName)
-	}
-
-	// If there is only one repository, we return all containers
-	if len(repoNames) == 1 {
-		return containers, repoNames[0]
-	}
-
-	// If there are no repositories, we return an empty slice
-	if len(repoNames) == 0 {
-		return []services.ContainerInformation{}, "*"
+func statusColor(status string) string {
+	switch status {
+	case "running":
+		return "\033[32m" // green
+	case "stopped", "exited":
+		return "\033[31m" // red
+	case "paused":
+		return "\033[33m" // yellow
+	default:
+		return "\033[36m" // cyan
 	}
+}
 
-	// Multiple repositories, prompt user
-	choices := append([]string{AllRepositories}, repoNames...)
-	selected := c.Choice("Select a repository:", choices...)
-	if selected == AllRepositories {
-		return containers, "*"
+func containerWideColumns(container orchestrator.ContainerInformation) table.Row {
+	ports := []string{}
+	for _, port := range container.Ports {
+		ports = append(ports, fmt.Sprint(port))
 	}
-	filtered := []services.ContainerInformation{}
-	for _, container := range containers {
-		if container.UmbrellaRepository == selected {
-			filtered = append(filtered, container)
-		}
-	}
-	return filtered, selected
+	return table.Row{strings.Join(ports, ","), container.NetworkName, container.Locator, container.ID}
 }
 
-func renderContainerTable(c inter.Cli, containers []services.ContainerInformation) {
-	ta := c.Table()
-	ta.AppendHeader(table.Row{"Environment", "Name", "target", "status"})
-	for _, container := range containers {
-		statusColor := "\033[32m" // green
-		switch container.Status {
-		case "running":
-			statusColor = "\033[32m" // green
-		case "stopped", "exited":
-			statusColor = "\033[31m" // red
-		case "paused":
-			statusColor = "\033[33m" // yellow
-		default:
-			statusColor = "\033[36m" // cyan
-		}
-		ta.AppendRow(table.Row{
-			fmt.Sprintf("\033[34m%s\033[0m", container.Environment.Name), // blue
-			fmt.Sprintf("\033[34m%s\033[0m", container.Name),             // blue
-			fmt.Sprintf("\033[34m%s\033[0m", container.Target),           // magenta
-			fmt.Sprintf("%s%s\033[0m", statusColor, container.Status),
-		})
+// printContainers prints the containers as json or yaml, e.g. to use in scripts.
+func printContainers(containers []orchestrator.ContainerInformation, output string) error {
+	var content []byte
+	var err error
+	if output == "yaml" {
+		content, err = yaml.Marshal(containers)
+	} else {
+		content, err = json.MarshalIndent(containers, "", "  ")
+		content = append(content, '\n')
 	}
-	ta.Render()
+	if err != nil {
+		return fmt.Errorf("unable to print containers as %s: %w", output, err)
+	}
+	fmt.Print(string(content))
+	return nil
 }
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"fmt"
+	"src/app/services"
+	"src/app/services/orchestrator"
+	"src/config"
+	"time"
+
+	"github.com/confetti-framework/framework/inter"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// highlightTransition is how long a status transition stays highlighted
+const highlightTransition = 10 * time.Second
+
+// watchContainers refreshes the table at every interval, or as soon as the
+// orchestrator pushes a change. It never returns: when a refresh fails (e.g.
+// the network is gone for a moment), the error is shown with the last known
+// containers and the refresh is tried again at the next tick.
+func watchContainers(c inter.Cli, runningEnv services.Environment, selection containerSelection, containers []orchestrator.ContainerInformation, interval time.Duration, wide bool) {
+	client := services.NewOrchestratorClient(c, runningEnv)
+	tracker := services.NewContainerStatusTracker()
+
+	// Refresh immediately when the orchestrator pushes a change. Without
+	// support for events, we only refresh at the interval.
+	changes := make(chan struct{}, 1)
+	go func() {
+		err := client.StreamContainerEvents(selection.query(), func(event orchestrator.ContainerEvent) error {
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+		if err != nil && config.App.VeryVerbose {
+			fmt.Printf("Container events not available, refresh every %s: %s\n", interval, err)
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var refreshErr error
+	for {
+		now := time.Now()
+		renderContainerDashboard(c, tracker.Update(containers, now), selection.Skipped, wide, now, interval, refreshErr)
+
+		select {
+		case <-ticker.C:
+		case <-changes:
+		}
+
+		_, refreshed, err := selection.selectContainers(c)
+		refreshErr = err
+		if err == nil {
+			containers = refreshed
+		}
+	}
+}
+
+func renderContainerDashboard(c inter.Cli, containers []services.TrackedContainer, skipped []string, wide bool, now time.Time, interval time.Duration, refreshErr error) {
+	// Clean entire screen
+	print("\033[H\033[2J")
+	fmt.Printf("\033[34mConfetti container:query --watch\033[0m  refreshed at %s, every %s (Ctrl+C to stop)\n\n", now.Format("15:04:05"), interval)
+	if refreshErr != nil {
+		fmt.Printf("\033[31mUnable to refresh, trying again in %s: %s\033[0m\n\n", interval, refreshErr) // red
+	}
+
+	ta := c.Table()
+	header := table.Row{"Environment", "Name", "target", "status", "for"}
+	if wide {
+		header = append(header, "ports", "network", "locator", "id")
+	}
+	ta.AppendHeader(header)
+	for _, container := range containers {
+		status := fmt.Sprintf("%s%s\033[0m", statusColor(container.Status), container.Status)
+		if !container.ChangedAt.IsZero() && now.Sub(container.ChangedAt) < highlightTransition {
+			// Bold and reversed, so the transition stands out
+			status = fmt.Sprintf("\033[1;7m%s → %s\033[0m", container.PreviousStatus, container.Status)
+		}
+		row := table.Row{
+			fmt.Sprintf("\033[34m%s\033[0m", container.Environment.Name), // blue
+			fmt.Sprintf("\033[34m%s\033[0m", container.Name),             // blue
+			fmt.Sprintf("\033[34m%s\033[0m", container.Target),           // magenta
+			status,
+			now.Sub(container.Since).Round(time.Second).String(),
+		}
+		if wide {
+			row = append(row, containerWideColumns(container.ContainerInformation)...)
+		}
+		ta.AppendRow(row)
+	}
+	ta.Render()
+	for _, message := range skipped {
+		fmt.Printf("\033[33m%s\033[0m\n", message) // yellow
+	}
+}
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"src/app/services"
+	"src/app/services/orchestrator"
+	"strings"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+// Wildcard selects all environments, organisations or repositories.
+const Wildcard = "*"
+
+// containerSelection holds the flags that commands use to select containers
+// in the same way as container:query. Empty values are asked to the user,
+// unless the command can't be interactive.
+type containerSelection struct {
+	Environment     string
+	AuthEnvironment string
+	Organisation    string
+	Repository      string
+	Filter          services.ContainerFilter
+	NoInteraction   bool
+	// CollectSkipped keeps the skipped containers in Skipped instead of
+	// printing them, e.g. to show them in the --watch dashboard
+	CollectSkipped bool
+	Skipped        []string
+}
+
+func (s containerSelection) interactive() bool {
+	return !s.NoInteraction && stdinIsTerminal()
+}
+
+// selectContainers returns the environment to authorize with and the
+// containers that match the selection. The choices of the user are stored
+// in the selection, so they can be shown to repeat the command.
+func (s *containerSelection) selectContainers(c inter.Cli) (services.Environment, []orchestrator.ContainerInformation, error) {
+	appConfig, err := services.GetAppConfig()
+	if err != nil {
+		return services.Environment{}, nil, err
+	}
+	names := []string{}
+	for _, environment := range appConfig.Environments {
+		names = append(names, environment.Name)
+	}
+	if len(names) == 0 {
+		return services.Environment{}, nil, fmt.Errorf("no environments found, please define an environment in your config.json5 file")
+	}
+
+	if s.Environment == "" {
+		switch {
+		case len(s.Filter.Locators) > 0:
+			// A locator already contains the environment
+			s.Environment = Wildcard
+		case len(names) == 1:
+			s.Environment = names[0]
+		case s.interactive():
+			s.Environment = c.Choice("You can narrow down your search by selecting an environment:", append([]string{AllEnvironments}, names...)...)
+			if s.Environment == AllEnvironments {
+				s.Environment = Wildcard
+			}
+		default:
+			return services.Environment{}, nil, fmt.Errorf("use --environment to select one of the environments (%s) or --environment=%s for all environments", strings.Join(names, ", "), Wildcard)
+		}
+	}
+
+	runningEnv, err := s.authEnvironment(c, names)
+	if err != nil {
+		return runningEnv, nil, err
+	}
+	s.AuthEnvironment = runningEnv.Name
+
+	containers, err := services.NewOrchestratorClient(c, runningEnv).ListContainers(s.query())
+	if err != nil {
+		return runningEnv, nil, fmt.Errorf("error getting containers: %w", err)
+	}
+
+	// Skip the containers that we can't work with, instead of failing on them
+	valid := []orchestrator.ContainerInformation{}
+	s.Skipped = nil
+	for _, container := range containers {
+		if reason := services.MalformedContainerReason(container); reason != "" {
+			message := fmt.Sprintf("Skipped container %q (locator %q) from the orchestrator: %s", container.Name, container.Locator, reason)
+			if s.CollectSkipped {
+				s.Skipped = append(s.Skipped, message)
+			} else {
+				// Use stderr to keep the output of --output=json valid
+				fmt.Fprintln(os.Stderr, message)
+			}
+			continue
+		}
+		valid = append(valid, container)
+	}
+	containers = services.FilterContainers(valid, s.Filter)
+
+	containers, s.Organisation, err = s.chooseUmbrella(c, containers, "organisation", s.Organisation, func(container orchestrator.ContainerInformation) string {
+		return container.UmbrellaOrganization
+	})
+	if err != nil {
+		return runningEnv, nil, err
+	}
+	containers, s.Repository, err = s.chooseUmbrella(c, containers, "repository", s.Repository, func(container orchestrator.ContainerInformation) string {
+		return container.UmbrellaRepository
+	})
+	if err != nil {
+		return runningEnv, nil, err
+	}
+	return runningEnv, containers, nil
+}
+
+func (s containerSelection) query() orchestrator.QueryContainerOptions {
+	query := orchestrator.QueryContainerOptions{Environment: s.Environment}
+	if s.Organisation != Wildcard {
+		query.UmbrellaOrganization = s.Organisation
+	}
+	if s.Repository != Wildcard {
+		query.UmbrellaRepository = s.Repository
+	}
+	return query
+}
+
+// authEnvironment returns the environment of config.json5 that is used to
+// check if the user is authorized to see the containers.
+func (s containerSelection) authEnvironment(c inter.Cli, names []string) (services.Environment, error) {
+	name := s.AuthEnvironment
+	if name == "" && services.ContainsString(names, s.Environment) {
+		name = s.Environment
+	}
+	if name == "" && len(names) > 1 {
+		if !s.interactive() {
+			return services.Environment{}, fmt.Errorf("use --auth-environment to select the environment (%s) to authorize with", strings.Join(names, ", "))
+		}
+		fmt.Printf("We couldn't find the environment by the query.\nPlease select an environment so we can check if you are authorized.\n")
+	}
+	return services.GetEnvironmentByInput(c, name)
+}
+
+// chooseUmbrella filters the containers by organisation or repository. When
+// nothing is selected and the containers are from multiple umbrellas, the
+// user has to choose.
+func (s containerSelection) chooseUmbrella(c inter.Cli, containers []orchestrator.ContainerInformation, kind string, selected string, get func(orchestrator.ContainerInformation) string) ([]orchestrator.ContainerInformation, string, error) {
+	if selected == Wildcard {
+		return containers, Wildcard, nil
+	}
+	if selected == "" {
+		nameMap := map[string]bool{}
+		for _, container := range containers {
+			nameMap[get(container)] = true
+		}
+		names := []string{}
+		for name := range nameMap {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		switch {
+		case len(names) == 0:
+			return containers, Wildcard, nil
+		case len(names) == 1:
+			return containers, names[0], nil
+		case !s.interactive():
+			return nil, "", fmt.Errorf("containers found in multiple %s, use --%s to select one of them (%s) or --%s=%s for all", pluralUmbrella(kind), kind, strings.Join(names, ", "), kind, Wildcard)
+		}
+		all := "all " + pluralUmbrella(kind)
+		selected = c.Choice(fmt.Sprintf("Select a %s:", kind), append([]string{all}, names...)...)
+		if selected == all {
+			return containers, Wildcard, nil
+		}
+	}
+	filtered := []orchestrator.ContainerInformation{}
+	for _, container := range containers {
+		if get(container) == selected {
+			filtered = append(filtered, container)
+		}
+	}
+	return filtered, selected, nil
+}
+
+func pluralUmbrella(kind string) string {
+	if kind == "repository" {
+		return "repositories"
+	}
+	return kind + "s"
+}
+
+const AllEnvironments = "all environments"
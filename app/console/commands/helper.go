@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/confetti-framework/errors"
+	"golang.org/x/term"
 )
 
 func getDirectoryOrCurrent(dir string) (string, error) {
@@ -29,3 +30,9 @@ func getDirectoryOrCurrent(dir string) (string, error) {
 func formatRootDir(dir string) string {
 	return strings.TrimRight(dir, config.App.LineSeparator) + config.App.LineSeparator
 }
+
+// stdinIsTerminal reports whether a user can answer questions. When the
+// command runs in a pipe or in CI, we can't prompt.
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
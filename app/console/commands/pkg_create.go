@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"src/app/services"
+	"src/config"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+type PkgCreate struct {
+	Directory       string `short:"dir" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Package         string `short:"p" flag:"package" description:"The package to create, e.g. 'confetti-cms/text'"`
+	About           string `flag:"description" description:"The description in composer.json and the README"`
+	Push            bool   `flag:"push" description:"Push the package as the first version of the repository in the package source"`
+	Verbose         bool   `short:"v" description:"Show events"`
+	VeryVerbose     bool   `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
+}
+
+func (p PkgCreate) Name() string {
+	return "pkg:create"
+}
+
+func (p PkgCreate) Description() string {
+	return "Creates a new package in pkg/ with a composer.json, a README and a component."
+}
+
+func (p PkgCreate) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = p.Verbose || p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVerbose = p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVeryVerbose = p.VeryVeryVerbose
+	root, err := getDirectoryOrCurrent(p.Directory)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	config.Path.Root = root
+
+	if config.App.Verbose {
+		c.Info("Use directory: %s", root)
+	}
+	fmt.Println("\n\033[34mConfetti pkg:create\n\033[0m") // blue
+	if p.Package == "" {
+		fmt.Fprintln(os.Stderr, "Error: -p or --package flag is required")
+		return inter.Failure
+	}
+	pkg := p.Package
+	err = services.ValidatePackageName(pkg)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+
+	// We commit the new package, so other changes can't be part of it
+	changes, err := services.HasModifications()
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	} else if len(changes) > 0 {
+		c.Info("Your project has %d modification(s) (e.g. %s), please commit or stash them first.", len(changes), changes[0])
+		return inter.Failure
+	}
+
+	// Check the upstream before anything is created, so we don't leave a half created package
+	var remote services.PackageRemote
+	if p.Push {
+		remote, err = services.GetPackageRemote(pkg, "")
+		if err != nil {
+			c.Error(err.Error())
+			return inter.Failure
+		}
+		err = services.InitUpstreamRepository(remote, services.DefaultPackageBranch)
+		if err != nil {
+			c.Error(err.Error())
+			return inter.Failure
+		}
+	}
+
+	files, err := services.ScaffoldPackage(pkg, p.About)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	for _, file := range files {
+		c.Line("Created %s", file)
+	}
+	err = services.StagePackage(pkg)
+	if err == nil {
+		err = services.CommitChanges(pkg, "Create package "+pkg)
+	}
+	if err != nil {
+		c.Error(fmt.Sprintf("Error committing package %s: %s", pkg, err))
+		return inter.Failure
+	}
+
+	if !p.Push {
+		c.Info("\nThe package %s is created 🎉", pkg)
+		c.Line("When the repository exists, push the first version with: \033[34mconf pkg:push -p %s\033[0m", pkg)
+		return inter.Success
+	}
+
+	c.Line("Pushing %s to %s...", pkg, remote.Url)
+	err = services.PushPackage(pkg, remote, services.DefaultPackageBranch)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	version, err := services.ResolvePackageVersion(remote, "")
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	lock, err := services.ReadPackageLock()
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	lock.Packages[pkg] = services.LockedPackage{PackageVersion: version, Source: remote.Url}
+	err = lock.Write()
+	if err == nil {
+		err = services.CommitChanges(pkg, fmt.Sprintf("Lock package %s at %s", pkg, packageVersionLabel(version)))
+	}
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+
+	c.Info("\nThe package %s is created and pushed to %s 🎉", pkg, remote.Url)
+	return inter.Success
+}
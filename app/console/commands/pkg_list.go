@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"src/app/services"
+	"src/config"
+
+	"github.com/confetti-framework/framework/inter"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type PkgList struct {
+	Directory       string `short:"dir" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Offline         bool   `flag:"offline" description:"Don't fetch the upstream repositories, so the commits that are not pulled yet are unknown"`
+	Verbose         bool   `short:"v" description:"Show events"`
+	VeryVerbose     bool   `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
+}
+
+func (p PkgList) Name() string {
+	return "pkg:list"
+}
+
+func (p PkgList) Description() string {
+	return "Lists the packages in pkg/ with their source and the commits that are not pushed or pulled yet."
+}
+
+func (p PkgList) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = p.Verbose || p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVerbose = p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVeryVerbose = p.VeryVeryVerbose
+	root, err := getDirectoryOrCurrent(p.Directory)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	config.Path.Root = root
+
+	if config.App.Verbose {
+		c.Info("Use directory: %s", root)
+	}
+	fmt.Println("\n\033[34mConfetti pkg:list\n\033[0m") // blue
+
+	packages, err := services.ListPackages()
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	if len(packages) == 0 {
+		c.Info("No packages found in pkg/, add one with: conf pkg:pull -p vendor/name")
+		return inter.Success
+	}
+
+	ta := c.Table()
+	ta.AppendHeader(table.Row{"Package", "Source", "Version", "Pulled", "Unpushed", "Not pulled"})
+	exitCode := inter.Success
+	for _, pkg := range packages {
+		status, err := services.GetPackageStatus(pkg, !p.Offline)
+		if err != nil {
+			// Show the other packages, e.g. when one upstream is unreachable
+			c.Error("%s: %s", pkg, err)
+			exitCode = inter.Failure
+		}
+		ta.AppendRow(table.Row{
+			fmt.Sprintf("\033[34m%s\033[0m", pkg), // blue
+			status.Source,
+			status.Version,
+			shortCommit(status.PulledCommit),
+			commitCount(status.LocalCommits, true),
+			commitCount(status.UpstreamCommits, status.Fetched),
+		})
+	}
+	ta.Render()
+	return exitCode
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	if commit == "" {
+		return "-"
+	}
+	return commit
+}
+
+// commitCount returns the number of commits, highlighted when there are any.
+func commitCount(commits []string, known bool) string {
+	if !known {
+		return "?"
+	}
+	if len(commits) == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("\033[33m%d\033[0m", len(commits)) // yellow
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"fmt"
+	"src/app/services"
+	"src/config"
+
+	"github.com/confetti-framework/framework/inter"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type PkgOutdated struct {
+	Directory       string `short:"dir" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Verbose         bool   `short:"v" description:"Show events"`
+	VeryVerbose     bool   `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
+}
+
+func (p PkgOutdated) Name() string {
+	return "pkg:outdated"
+}
+
+func (p PkgOutdated) Description() string {
+	return "Shows how many versions and commits the packages in pkg/ are behind upstream."
+}
+
+func (p PkgOutdated) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = p.Verbose || p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVerbose = p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVeryVerbose = p.VeryVeryVerbose
+	root, err := getDirectoryOrCurrent(p.Directory)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	config.Path.Root = root
+
+	if config.App.Verbose {
+		c.Info("Use directory: %s", root)
+	}
+	fmt.Println("\n\033[34mConfetti pkg:outdated\n\033[0m") // blue
+
+	packages, err := services.ListPackages()
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	if len(packages) == 0 {
+		c.Info("No packages found in pkg/, add one with: conf pkg:pull -p vendor/name")
+		return inter.Success
+	}
+
+	ta := c.Table()
+	ta.AppendHeader(table.Row{"Package", "Current", "Wanted", "Latest", "Versions behind", "Commits behind"})
+	exitCode := inter.Success
+	outdated := 0
+	for _, pkg := range packages {
+		result, err := services.CheckPackageOutdated(pkg)
+		if err != nil {
+			// Show the other packages, e.g. when one upstream is unreachable
+			c.Error("%s: %s", pkg, err)
+			exitCode = inter.Failure
+			continue
+		}
+		if result.VersionsBehind > 0 || result.CommitsBehind > 0 {
+			outdated++
+		}
+		ta.AppendRow(table.Row{
+			fmt.Sprintf("\033[34m%s\033[0m", pkg), // blue
+			result.Current,
+			result.Wanted,
+			result.Latest,
+			behindCount(result.VersionsBehind),
+			behindCount(result.CommitsBehind),
+		})
+	}
+	ta.Render()
+
+	if outdated > 0 {
+		c.Line("\nPull the wanted versions with: \033[34mconf pkg:update\033[0m")
+	}
+	return exitCode
+}
+
+// behindCount highlights a count that is not zero.
+func behindCount(count int) string {
+	if count == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("\033[33m%d\033[0m", count) // yellow
+}
@@ -1,18 +1,23 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"src/app/services"
 	"src/config"
+	"strings"
 
 	"github.com/confetti-framework/framework/inter"
+	"github.com/jedib0t/go-pretty/v6/table"
 )
 
 type PkgPull struct {
 	Directory       string `short:"dir" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
-	Package         string `short:"p" flag:"package" description:"The package to pull, e.g. 'confetti-cms/text'"`
+	Package         string `short:"p" flag:"package" description:"The package to pull, e.g. 'confetti-cms/text'. Add a tag, branch or constraint to pull a specific version, e.g. 'confetti-cms/text@^1.2'. Use the url of a repository for a source that is not in config.json5"`
+	All             bool   `short:"a" flag:"all" description:"Pull all packages in pkg/, the same as pkg:update"`
+	Abort           bool   `flag:"abort" description:"Abort a pull with conflicts and restore the project"`
 	Verbose         bool   `short:"v" description:"Show events"`
 	VeryVerbose     bool   `short:"vv" description:"Show more events"`
 	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
@@ -23,7 +28,7 @@ func (p PkgPull) Name() string {
 }
 
 func (p PkgPull) Description() string {
-	return "Pulls the latest changes for a package."
+	return "Pulls the latest changes for a package, within the version constraint in confetti.lock."
 }
 
 func (p PkgPull) Handle(c inter.Cli) inter.ExitCode {
@@ -41,21 +46,97 @@ func (p PkgPull) Handle(c inter.Cli) inter.ExitCode {
 		c.Info("Use directory: %s", root)
 	}
 	fmt.Println("\n\033[34mConfetti pkg:pull\n\033[0m") // blue
+	if p.Abort {
+		err = services.AbortPackageMerge()
+		if err != nil {
+			c.Error(err.Error())
+			return inter.Failure
+		}
+		c.Info("The pull is aborted, the project is restored")
+		return inter.Success
+	}
+	if p.All {
+		return pullAllPackages(c)
+	}
 	if p.Package == "" {
-		fmt.Fprintln(os.Stderr, "Error: -pkg or --package flag is required")
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Error: -p or --package flag is required, or use --all to pull all packages")
+		return inter.Failure
+	}
+	pkg, urlSource, requested, err := services.ParsePackageArgument(p.Package)
+	if err == nil {
+		err = services.ValidatePackageName(pkg)
+	}
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
 	}
 
-	if pkgDirExists(p.Package) && !pkgHasFiles(p.Package) {
+	if pkgDirExists(pkg) && !pkgHasFiles(pkg) {
 		// Remove the empty package directory since it is confusing for the system
-		c.Line("Removed empty package directory: %s", p.Package)
-		err = services.RemovePackage(p.Package, "Removed empty package directory sdk/"+p.Package)
+		c.Line("Removed empty package directory: %s", pkg)
+		err = services.RemovePackage(pkg, "Removed empty package directory sdk/"+pkg)
 		if err != nil {
-			c.Error(fmt.Sprintf("Error committing changes for package %s: %s", p.Package, err))
+			c.Error(fmt.Sprintf("Error committing changes for package %s: %s", pkg, err))
 			return inter.Failure
 		}
 	}
 
+	if !projectIsReadyToPull(c) {
+		return inter.Failure
+	}
+
+	result, err := pullPackage(c, pkg, urlSource, requested, true)
+	if result.Result == pullConflict {
+		// The user has already chosen what to do with the conflicts
+		return inter.Failure
+	}
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+
+	dependencies, err := pullDependencies(c, pkg, map[string]bool{}, true)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	if result.Result == pullUpToDate && len(dependencies) == 0 {
+		return inter.Success
+	}
+
+	composerPkgs := []string{}
+	for _, r := range append([]pullResult{result}, dependencies...) {
+		if r.NeedsComposer && r.Result != pullUpToDate {
+			composerPkgs = append(composerPkgs, r.Package)
+		}
+	}
+	if len(composerPkgs) > 0 {
+		err = requireInComposer(c, composerPkgs...)
+		if err != nil {
+			c.Error(err.Error())
+			return inter.Failure
+		}
+	}
+	if result.Result == pullUpToDate {
+		return inter.Success
+	}
+
+	// Print success message
+	if result.Result == pullAdded {
+		services.PrintPackageInstalledMessage(pkg)
+	} else {
+		services.PrintPackagePulledMessage(pkg)
+	}
+	return inter.Success
+}
+
+// projectIsReadyToPull checks that a pull can be committed without other changes.
+func projectIsReadyToPull(c inter.Cli) bool {
+	if services.MergeInProgress() {
+		c.Error("A pull is still in progress, resolve the conflicts and commit them, or undo the pull with: conf pkg:pull --abort")
+		return false
+	}
+
 	// Check if the environment has changes
 	if config.App.Verbose {
 		c.Line("Checking for modifications...")
@@ -63,30 +144,90 @@ func (p PkgPull) Handle(c inter.Cli) inter.ExitCode {
 	changes, err := services.HasModifications()
 	if err != nil {
 		c.Error(err.Error())
-		return inter.Failure
+		return false
 	} else if len(changes) > 0 {
 		if len(changes) == 1 {
 			c.Info("Your project has 1 modification (%s), please commit or stash it first.", changes[0])
 		} else {
 			c.Info("Your project has %d modifications (e.g. %s), please commit or stash them first.", len(changes), changes[0])
 		}
-		return inter.Failure
+		return false
+	}
+	return true
+}
+
+const (
+	pullAdded    = "added"
+	pullUpdated  = "updated"
+	pullUpToDate = "up to date"
+	pullConflict = "conflict"
+	pullFailed   = "failed"
+)
+
+type pullResult struct {
+	Package string
+	Before  string
+	After   string
+	Result  string
+	// NeedsComposer is true when the package has PHP files, so it has to be required in composer.json
+	NeedsComposer bool
+}
+
+// pullPackage adds or pulls one package and commits it with the lockfile.
+// With interactive, the user can resolve conflicts. Otherwise the pull is
+// aborted on conflicts, so the next package can be pulled.
+func pullPackage(c inter.Cli, pkg, urlSource, requested string, interactive bool) (pullResult, error) {
+	result := pullResult{Package: pkg, Result: pullUpdated}
+	if !pkgDirExists(pkg) {
+		result.Result = pullAdded
+	}
+
+	// Without a version, we upgrade within the constraint of the lockfile
+	lock, err := services.ReadPackageLock()
+	if err != nil {
+		return result, err
+	}
+	locked, isLocked := lock.Packages[pkg]
+	if isLocked {
+		result.Before = packageVersionLabel(locked.PackageVersion)
+	}
+	if requested == "" && isLocked {
+		requested = locked.Constraint
+	}
+	// A full url is only used for this package, the next pull uses the url from the lockfile
+	remote := services.PackageRemote{Url: urlSource}
+	if urlSource == "" {
+		remote, err = services.GetPackageRemote(pkg, locked.Source)
+		if err != nil {
+			return result, err
+		}
+	}
+	if config.App.Verbose {
+		c.Line("Resolving version %q of %s...", requested, remote.Url)
+	}
+	version, err := services.ResolvePackageVersion(remote, requested)
+	if err != nil {
+		return result, err
+	}
+	result.After = packageVersionLabel(version)
+	if result.Result != pullAdded && isLocked && version.Commit != "" && locked.Commit == version.Commit && locked.Constraint == version.Constraint {
+		c.Info("Package %s is already up to date (%s)", pkg, packageVersionLabel(version))
+		result.Result = pullUpToDate
+		return result, nil
 	}
-	pkgDirIsNew := !pkgDirExists(p.Package)
 
 	// Check if the package directory exists
 	if config.App.VeryVerbose {
 		c.Line("Checking if package directory exists...")
 	}
-	if !pkgDirExists(p.Package) {
+	if !pkgDirExists(pkg) {
 		c.Line("Package directory does not exist, trying to restore if it was pulled in the past...")
-		restored, err := services.RestoreDirectory(p.Package)
+		restored, err := services.RestoreDirectory(pkg)
 		if err != nil {
-			c.Error(fmt.Sprintf("Error restoring directory for package `%s`: %s", p.Package, err))
-			return inter.Failure
+			return result, fmt.Errorf("error restoring directory for package `%s`: %w", pkg, err)
 		}
 		if restored {
-			c.Info("Restored package %s successfully.", p.Package)
+			c.Info("Restored package %s successfully.", pkg)
 		}
 	}
 
@@ -94,65 +235,215 @@ func (p PkgPull) Handle(c inter.Cli) inter.ExitCode {
 	if config.App.VeryVerbose {
 		c.Line("Checking if package directory exists after restoration...")
 	}
-	if !pkgDirExists(p.Package) {
+	if !pkgDirExists(pkg) {
 		c.Line("Package directory does not exist, pulling it for the first time...")
-		err := services.AddNewPackage(p.Package)
+		err = services.AddNewPackage(pkg, remote, version.Ref)
 		if err != nil {
-			c.Error(fmt.Sprintf("Error adding new package %s: %s", p.Package, err))
-			return inter.Failure
+			return result, fmt.Errorf("error adding new package %s: %w", pkg, err)
+		}
+	} else {
+		// Pull the latest changes for the package
+		err = services.PullLatestChanges(pkg, remote, version.Ref)
+	}
+	var conflict *services.PackageConflictError
+	if errors.As(err, &conflict) {
+		if !interactive {
+			result.Result = pullConflict
+			abortErr := services.AbortPackageMerge()
+			if abortErr != nil {
+				return result, abortErr
+			}
+			return result, fmt.Errorf("%w, the pull is aborted. Pull it with `conf pkg:pull -p %s` to resolve the conflicts", err, pkg)
+		}
+		if !resolvePullConflict(c, conflict) {
+			result.Result = pullConflict
+			return result, err
 		}
+	} else if err != nil {
+		return result, fmt.Errorf("error pulling latest changes for package %s: %w", pkg, err)
 	}
 
-	// Pull the latest changes for the package
-	err = services.PullLatestChanges(p.Package)
+	// Record the version, so everyone pulls the same commit
+	lock.Packages[pkg] = services.LockedPackage{PackageVersion: version, Source: remote.Url}
+	err = lock.Write()
 	if err != nil {
-		c.Error(fmt.Sprintf("Error pulling latest changes for package %s: %s", p.Package, err))
-		return inter.Failure
+		return result, err
+	}
+	err = services.CommitChanges(pkg, fmt.Sprintf("Lock package %s at %s", pkg, packageVersionLabel(version)))
+	if err != nil {
+		return result, fmt.Errorf("error committing %s for package %s: %w", services.PackageLockFile, pkg, err)
+	}
+	c.Info("Locked %s at %s", pkg, packageVersionLabel(version))
+
+	result.NeedsComposer = services.PkgPackageContainsPhpFiles(pkg)
+	return result, nil
+}
+
+// pullDependencies pulls the packages that pkg requires (see
+// services.PackageDependencies), and the packages that they require. Packages
+// in visited are already pulled in this run, so they are pulled only once.
+func pullDependencies(c inter.Cli, pkg string, visited map[string]bool, interactive bool) ([]pullResult, error) {
+	results := []pullResult{}
+	cycles, err := services.WalkPackageDependencies(pkg, visited, func(dependency services.PackageDependency) error {
+		c.Line("\n%s requires \033[34m%s\033[0m %s", dependency.RequiredBy, dependency.Package, dependency.Constraint)
+		result, err := pullPackage(c, dependency.Package, "", dependency.Constraint, interactive)
+		if err != nil && result.Result != pullConflict {
+			result.Result = pullFailed
+		}
+		results = append(results, result)
+		return err
+	})
+	for _, cycle := range cycles {
+		c.Comment("Dependency cycle %s, %s is pulled once", strings.Join(cycle, " -> "), cycle[len(cycle)-1])
 	}
+	return results, err
+}
 
-	if services.PkgPackageContainsPhpFiles(p.Package) {
-		c.Line("Package contains PHP files, running composer update...")
+// requireInComposer adds the packages to composer.json and the autoloader with one composer run.
+func requireInComposer(c inter.Cli, pkgs ...string) error {
+	c.Line("Package contains PHP files, running composer update...")
+	for _, pkg := range pkgs {
 		// Check if the package directory has a composer.json file
 		if config.App.Verbose {
 			c.Line("Checking if package directory has a composer.json file...")
 		}
-		pkgDir := filepath.Join(config.Path.Root, "pkg", p.Package)
-		_, err = os.Stat(filepath.Join(pkgDir, "composer.json"))
+		pkgDir := filepath.Join(config.Path.Root, "pkg", pkg)
+		_, err := os.Stat(filepath.Join(pkgDir, "composer.json"))
 		if os.IsNotExist(err) {
-			services.PrintPackageNoComposerMessage(p.Package)
-			return inter.Failure
+			services.PrintPackageNoComposerMessage(pkg)
+			return fmt.Errorf("package %s has no composer.json", pkg)
 		} else if err != nil {
-			c.Error(fmt.Sprintf("Error checking for composer.json in package directory %s: %s", pkgDir, err))
-			return inter.Failure
+			return fmt.Errorf("error checking for composer.json in package directory %s: %w", pkgDir, err)
 		}
+	}
 
-		// Update composer.json and add the package to the autoloader
-		err = services.UpdateComposer(p.Package)
-		if err != nil {
-			c.Error("Failed to update composer.json and add the package to the autoloader: %s", err)
+	// Update composer.json and add the package to the autoloader
+	err := services.UpdateComposer(pkgs...)
+	if err != nil {
+		return fmt.Errorf("failed to update composer.json and add the package to the autoloader: %w", err)
+	}
 
-			return inter.Failure
+	// composer require has updated the vendor directory to the new composer.lock
+	err = services.RecordVendorLockHash()
+	if err != nil {
+		c.Error(err.Error())
+	}
+
+	// Add the package to the Git index
+	err = services.CommitChanges(pkgs[0], "Added package to composer "+strings.Join(pkgs, ", "))
+	if err != nil {
+		return fmt.Errorf("error committing changes for package %s: %w", strings.Join(pkgs, ", "), err)
+	}
+	return nil
+}
+
+// pullAllPackages pulls the packages in pkg/ one by one. A package that
+// fails is restored, so the other packages are still pulled.
+func pullAllPackages(c inter.Cli) inter.ExitCode {
+	if !projectIsReadyToPull(c) {
+		return inter.Failure
+	}
+	packages, err := services.ListPackages()
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	if len(packages) == 0 {
+		c.Info("No packages found in pkg/, add one with: conf pkg:pull -p vendor/name")
+		return inter.Success
+	}
+
+	results := []pullResult{}
+	errs := map[string]error{}
+	composerPkgs := []string{}
+	visited := map[string]bool{}
+	for _, pkg := range packages {
+		if visited[pkg] {
+			// Already pulled as a dependency of another package
+			continue
+		}
+		c.Line("\n\033[34m%s\033[0m", pkg)
+		result, err := pullPackage(c, pkg, "", "", false)
+		var dependencies []pullResult
+		if err == nil {
+			var dependencyErr error
+			dependencies, dependencyErr = pullDependencies(c, pkg, visited, false)
+			if dependencyErr != nil {
+				c.Error(dependencyErr.Error())
+				errs[pkg] = dependencyErr
+				// The dependencies that are pulled are already committed
+				restoreErr := services.RestoreProject()
+				if restoreErr != nil {
+					c.Error(restoreErr.Error())
+					results = append(append(results, result), dependencies...)
+					break
+				}
+			}
+			for _, dependency := range dependencies {
+				if dependency.NeedsComposer && dependency.Result != pullUpToDate && dependency.Result != pullFailed && dependency.Result != pullConflict {
+					composerPkgs = append(composerPkgs, dependency.Package)
+				}
+			}
+		}
+		if err != nil {
+			c.Error(err.Error())
+			if result.Result != pullConflict {
+				result.Result = pullFailed
+			}
+			errs[pkg] = err
+			restoreErr := services.RestoreProject()
+			if restoreErr != nil {
+				// The next pulls would fail on the modifications
+				c.Error(restoreErr.Error())
+				results = append(results, result)
+				break
+			}
+		} else if result.NeedsComposer && result.Result != pullUpToDate {
+			composerPkgs = append(composerPkgs, pkg)
 		}
+		results = append(append(results, result), dependencies...)
+	}
 
-		// Add the package to the Git index
-		err = services.CommitChanges(p.Package, "Added package to composer "+p.Package)
+	if len(composerPkgs) > 0 {
+		err = requireInComposer(c, composerPkgs...)
 		if err != nil {
-			c.Error(fmt.Sprintf("Error committing changes for package %s: %s", p.Package, err))
-			return inter.Failure
+			c.Error(err.Error())
+			errs["composer"] = err
 		}
 	}
 
-	// Print success message
-	if pkgDirIsNew {
-		services.PrintPackageInstalledMessage(p.Package)
-	} else {
-		services.PrintPackagePulledMessage(p.Package)
+	c.Line("")
+	ta := c.Table()
+	ta.AppendHeader(table.Row{"Package", "Before", "After", "Result"})
+	for _, result := range results {
+		ta.AppendRow(table.Row{
+			fmt.Sprintf("\033[34m%s\033[0m", result.Package), // blue
+			result.Before,
+			result.After,
+			pullResultColor(result.Result) + result.Result + "\033[0m",
+		})
 	}
+	ta.Render()
 
-	// The watch is preventing the code from ever getting here
+	if len(errs) > 0 {
+		return inter.Failure
+	}
 	return inter.Success
 }
 
+func pullResultColor(result string) string {
+	switch result {
+	case pullAdded, pullUpdated:
+		return "\033[32m" // green
+	case pullConflict:
+		return "\033[33m" // yellow
+	case pullFailed:
+		return "\033[31m" // red
+	default:
+		return ""
+	}
+}
+
 func pkgDirExists(pkg string) bool {
 	dir := filepath.Join(config.Path.Root, "pkg", pkg)
 	if config.App.VeryVeryVerbose {
@@ -171,6 +462,80 @@ func pkgDirExists(pkg string) bool {
 	return info.IsDir()
 }
 
+const (
+	conflictAbort   = "Abort the pull and restore the project"
+	conflictPerFile = "Keep the upstream or the project version per file"
+	conflictManual  = "Resolve the conflicts manually"
+	conflictTheirs  = "Keep upstream (theirs)"
+	conflictOurs    = "Keep project (ours)"
+)
+
+// resolvePullConflict lets the user choose how to continue with the
+// conflicts. It returns true when the conflicts are resolved and the merge is committed.
+func resolvePullConflict(c inter.Cli, conflict *services.PackageConflictError) bool {
+	c.Error("The upstream changes of %s conflict with the changes in your project:", conflict.Package)
+	for _, file := range conflict.Files {
+		c.Line("  %s", file)
+	}
+	c.Line("")
+
+	choice := conflictManual
+	if stdinIsTerminal() {
+		choice = c.Choice("How do you want to continue?", conflictAbort, conflictPerFile, conflictManual)
+	}
+	switch choice {
+	case conflictAbort:
+		err := services.AbortPackageMerge()
+		if err != nil {
+			c.Error(err.Error())
+			return false
+		}
+		c.Info("The pull is aborted, the project is restored")
+		return false
+	case conflictPerFile:
+		for _, file := range conflict.Files {
+			keep := c.Choice(fmt.Sprintf("Which version of %s do you want to keep?", file), conflictTheirs, conflictOurs)
+			err := services.ResolvePackageConflict(conflict.Package, file, keep == conflictTheirs)
+			if err != nil {
+				c.Error(err.Error())
+				printManualConflictResolution(c, conflict.Package)
+				return false
+			}
+		}
+		err := services.ConcludePackageMerge(conflict.Package)
+		if err != nil {
+			c.Error(err.Error())
+			printManualConflictResolution(c, conflict.Package)
+			return false
+		}
+		c.Info("All conflicts are resolved")
+		return true
+	}
+	printManualConflictResolution(c, conflict.Package)
+	return false
+}
+
+func printManualConflictResolution(c inter.Cli, pkg string) {
+	c.Line("The pull is still in progress. Resolve the conflicts in pkg/%s, then run:", pkg)
+	c.Line("")
+	c.Line("\033[32mgit add pkg/%s\033[0m", pkg)
+	c.Line("\033[32mgit commit --no-edit\033[0m")
+	c.Line("\033[32mconf pkg:pull -p %s\033[0m  (to update %s)", pkg, services.PackageLockFile)
+	c.Line("")
+	c.Line("Or undo the pull with: \033[34mconf pkg:pull --abort\033[0m")
+}
+
+// packageVersionLabel returns the tag, or the branch with the commit, e.g. main (1a2b3c4).
+func packageVersionLabel(version services.PackageVersion) string {
+	if version.Version != "" {
+		return version.Version
+	}
+	if len(version.Commit) >= 7 {
+		return fmt.Sprintf("%s (%s)", version.Ref, version.Commit[:7])
+	}
+	return version.Ref
+}
+
 // pkgHasContent checks if the package directory has any files or subdirectories.
 func pkgHasFiles(pkg string) bool {
 	files, err := os.ReadDir(filepath.Join(config.Path.Root, "pkg", pkg))
@@ -12,6 +12,10 @@ import (
 type PkgPush struct {
 	Directory       string `short:"dir" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
 	Package         string `short:"p" flag:"package" description:"The package to push, e.g. 'confetti-cms/text'"`
+	Branch          string `short:"b" flag:"branch" description:"The branch to push to, defaults to the locked branch or main"`
+	DryRun          bool   `flag:"dry-run" description:"Show the commits that will be pushed, without pushing them"`
+	ForceWithLease  bool   `flag:"force-with-lease" description:"Overwrite the branch, unless it has changed since the commits are compared"`
+	Rejoin          bool   `flag:"rejoin" description:"Merge the split back into the project, so the next push doesn't have to split the whole history"`
 	Verbose         bool   `short:"v" description:"Show events"`
 	VeryVerbose     bool   `short:"vv" description:"Show more events"`
 	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
@@ -41,9 +45,14 @@ func (p PkgPush) Handle(c inter.Cli) inter.ExitCode {
 	}
 	fmt.Println("\n\033[34mConfetti pkg:push\n\033[0m") // blue
 	if p.Package == "" {
-		fmt.Fprintln(os.Stderr, "Error: -pkg or --package flag is required")
+		fmt.Fprintln(os.Stderr, "Error: -p or --package flag is required")
 		services.PlayErrorSound()
-		os.Exit(1)
+		return inter.Failure
+	}
+	err = services.ValidatePackageName(p.Package)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
 	}
 
 	// Check if the package contains any files
@@ -62,9 +71,69 @@ func (p PkgPush) Handle(c inter.Cli) inter.ExitCode {
 		c.Line("Package directory exists: %s", p.Package)
 	}
 
-	// Push the package to the remote repository
-	err = services.PushPackage(p.Package)
+	// Only committed changes are pushed, so uncommitted changes would silently stay behind
+	changes, err := services.PackageUncommittedChanges(p.Package)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	if len(changes) > 0 {
+		c.Error("Package %s has %d uncommitted change(s), please commit or stash them first:", p.Package, len(changes))
+		for _, change := range changes {
+			c.Line("  %s", change)
+		}
+		return inter.Failure
+	}
+
+	// A package that is locked at a branch is pushed to that branch, tags can't be pushed to
+	lock, err := services.ReadPackageLock()
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	locked, isLocked := lock.Packages[p.Package]
+	remote, err := services.GetPackageRemote(p.Package, locked.Source)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	branch := p.Branch
+	if branch == "" {
+		branch = services.DefaultPackageBranch
+		if isLocked && locked.Version == "" && locked.Ref != "" {
+			branch = locked.Ref
+		}
+	}
+
+	c.Line("Comparing %s with branch %s of %s...", p.Package, branch, remote.Url)
+	plan, err := services.PlanPackagePush(p.Package, remote, branch, p.Rejoin && !p.DryRun)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	if len(plan.Commits) == 0 {
+		c.Info("Nothing to push, branch %s is up to date", branch)
+		return inter.Success
+	}
+	printCommits(c, fmt.Sprintf("Commits to push to %s", branch), plan.Commits)
+	if !plan.FastForward {
+		c.Comment("\nBranch %s has commits that are not pulled yet", branch)
+		if !p.ForceWithLease {
+			c.Error("Pull them first with \033[34mconf pkg:pull -p %s@%s\033[0m, or overwrite them with --force-with-lease", p.Package, branch)
+			return inter.Failure
+		}
+	}
+	if p.DryRun {
+		c.Info("\nDry run, nothing is pushed")
+		return inter.Success
+	}
 
-	// The watch is preventing the code from ever getting here
+	err = services.ExecutePackagePush(remote, plan, p.ForceWithLease)
+	if err != nil {
+		c.Error(err.Error())
+		services.PlayErrorSound()
+		return inter.Failure
+	}
+	c.Info("\nThe package %s is pushed to %s 🚀", p.Package, branch)
 	return inter.Success
 }
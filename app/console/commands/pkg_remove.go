@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"src/app/services"
+	"src/config"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+type PkgRemove struct {
+	Directory       string `short:"dir" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Package         string `short:"p" flag:"package" description:"The package to remove, e.g. 'confetti-cms/text'"`
+	Force           bool   `short:"f" flag:"force" description:"Remove the package, even when it has local commits that are not pushed"`
+	Verbose         bool   `short:"v" description:"Show events"`
+	VeryVerbose     bool   `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
+}
+
+func (p PkgRemove) Name() string {
+	return "pkg:remove"
+}
+
+func (p PkgRemove) Description() string {
+	return "Removes a package from pkg/, composer and the lockfile."
+}
+
+func (p PkgRemove) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = p.Verbose || p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVerbose = p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVeryVerbose = p.VeryVeryVerbose
+	root, err := getDirectoryOrCurrent(p.Directory)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	config.Path.Root = root
+
+	if config.App.Verbose {
+		c.Info("Use directory: %s", root)
+	}
+	fmt.Println("\n\033[34mConfetti pkg:remove\n\033[0m") // blue
+	if p.Package == "" {
+		fmt.Fprintln(os.Stderr, "Error: -p or --package flag is required")
+		return inter.Failure
+	}
+	pkg := p.Package
+	// A name like .. or only the vendor would remove more than one package
+	err = services.ValidatePackageName(pkg)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	if !pkgDirExists(pkg) {
+		c.Error("Package directory 'pkg/%s' does not exist.", pkg)
+		return inter.Failure
+	}
+
+	// We commit the removal, so other changes can't be part of it
+	changes, err := services.HasModifications()
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	} else if len(changes) > 0 {
+		c.Info("Your project has %d modification(s) (e.g. %s), please commit or stash them first.", len(changes), changes[0])
+		return inter.Failure
+	}
+
+	if !p.Force {
+		status, err := services.GetPackageStatus(pkg, true)
+		if err != nil {
+			c.Comment("Unable to compare with upstream, checking the local commits only: %s", err)
+			status, err = services.GetPackageStatus(pkg, false)
+			if err != nil {
+				c.Error(err.Error())
+				return inter.Failure
+			}
+		}
+		if len(status.LocalCommits) > 0 {
+			c.Error("Package %s has %d local commit(s) that are not pushed:", pkg, len(status.LocalCommits))
+			for _, commit := range status.LocalCommits {
+				c.Line("  %s", commit)
+			}
+			c.Line("\nPush them first with \033[34mconf pkg:push -p %s\033[0m, or use --force to remove the package anyway.", pkg)
+			return inter.Failure
+		}
+	}
+
+	err = removeFromComposer(c, pkg)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+
+	err = os.RemoveAll(filepath.Join(config.Path.Root, "pkg", pkg))
+	if err != nil {
+		c.Error("Error removing package %s: %s", pkg, err)
+		return inter.Failure
+	}
+	// Remove the vendor directory when it was the last package of the vendor
+	_ = os.Remove(filepath.Dir(filepath.Join(config.Path.Root, "pkg", pkg)))
+
+	lock, err := services.ReadPackageLock()
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	if _, ok := lock.Packages[pkg]; ok {
+		delete(lock.Packages, pkg)
+		err = lock.Write()
+		if err != nil {
+			c.Error(err.Error())
+			return inter.Failure
+		}
+	}
+
+	err = services.CommitChanges(pkg, fmt.Sprintf("Remove package %s", pkg))
+	if err != nil {
+		c.Error(fmt.Sprintf("Error committing the removal of package %s: %s", pkg, err))
+		return inter.Failure
+	}
+
+	c.Info("The package %s is removed 🧹", pkg)
+	return inter.Success
+}
+
+// removeFromComposer removes the package from the requirements and the autoloader.
+func removeFromComposer(c inter.Cli, pkg string) error {
+	required, err := services.ComposerRequires(pkg)
+	if err != nil {
+		return err
+	}
+	autoloadChanged, err := services.RemoveComposerAutoload(pkg)
+	if err != nil {
+		return err
+	}
+	if autoloadChanged {
+		c.Line("Removed the autoload entries of %s from composer.json", pkg)
+	}
+
+	_, composerErr := exec.LookPath("composer")
+	if required {
+		if composerErr != nil {
+			return fmt.Errorf("composer.json requires %s, install composer to remove it", pkg)
+		}
+		c.Line("Running composer remove %s...", pkg)
+		err = services.ComposerRemove(pkg)
+		if err != nil {
+			return err
+		}
+	} else if autoloadChanged && composerErr == nil {
+		err = services.ComposerDumpAutoload()
+		if err != nil {
+			return err
+		}
+	}
+
+	// composer remove has updated the vendor directory to the new composer.lock
+	return services.RecordVendorLockHash()
+}
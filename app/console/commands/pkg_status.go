@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"src/app/services"
+	"src/config"
+	"strings"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+type PkgStatus struct {
+	Directory       string `short:"dir" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Package         string `short:"p" flag:"package" description:"The package to show, e.g. 'confetti-cms/text'"`
+	Offline         bool   `flag:"offline" description:"Don't fetch the upstream repository, so the commits that are not pulled yet are unknown"`
+	Verbose         bool   `short:"v" description:"Show events"`
+	VeryVerbose     bool   `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
+}
+
+func (p PkgStatus) Name() string {
+	return "pkg:status"
+}
+
+func (p PkgStatus) Description() string {
+	return "Shows the changes of a package since the last pull, in the project and upstream."
+}
+
+func (p PkgStatus) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = p.Verbose || p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVerbose = p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVeryVerbose = p.VeryVeryVerbose
+	root, err := getDirectoryOrCurrent(p.Directory)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	config.Path.Root = root
+
+	if config.App.Verbose {
+		c.Info("Use directory: %s", root)
+	}
+	fmt.Println("\n\033[34mConfetti pkg:status\n\033[0m") // blue
+	if p.Package == "" {
+		fmt.Fprintln(os.Stderr, "Error: -p or --package flag is required")
+		return inter.Failure
+	}
+	err = services.ValidatePackageName(p.Package)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	if !pkgDirExists(p.Package) {
+		c.Error("Package directory 'pkg/%s' does not exist.", p.Package)
+		return inter.Failure
+	}
+
+	status, err := services.GetPackageStatus(p.Package, !p.Offline)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	local, upstream, err := services.PackageDiffStat(status)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+
+	c.Line("Package:  %s", status.Package)
+	c.Line("Source:   %s", status.Source)
+	if status.Version != "" {
+		c.Line("Version:  %s", status.Version)
+	}
+	if status.PulledCommit == "" {
+		c.Line("Pulled:   never, the package is not added with pkg:pull")
+	} else {
+		c.Line("Pulled:   %s (in %s)", shortCommit(status.PulledCommit), shortCommit(status.PullCommit))
+	}
+
+	printCommits(c, "Local commits that are not pushed", status.LocalCommits)
+	if strings.TrimSpace(local) != "" {
+		c.Line("")
+		c.Comment("Changed in the project since the last pull:")
+		fmt.Print(local)
+	}
+	if status.Fetched {
+		printCommits(c, "Upstream commits that are not pulled", status.UpstreamCommits)
+		if strings.TrimSpace(upstream) != "" {
+			c.Line("")
+			c.Comment("Changed upstream since the last pull:")
+			fmt.Print(upstream)
+		}
+	}
+	return inter.Success
+}
+
+func printCommits(c inter.Cli, title string, commits []string) {
+	c.Line("")
+	if len(commits) == 0 {
+		c.Info("%s: none", title)
+		return
+	}
+	c.Comment("%s (%d):", title, len(commits))
+	for _, commit := range commits {
+		c.Line("  %s", commit)
+	}
+}
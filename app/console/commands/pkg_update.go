@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"fmt"
+	"src/config"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+type PkgUpdate struct {
+	Directory       string `short:"dir" flag:"directory" description:"Root directory of the project, defaults to the current directory"`
+	Verbose         bool   `short:"v" description:"Show events"`
+	VeryVerbose     bool   `short:"vv" description:"Show more events"`
+	VeryVeryVerbose bool   `short:"vvv" description:"Show all events"`
+}
+
+func (p PkgUpdate) Name() string {
+	return "pkg:update"
+}
+
+func (p PkgUpdate) Description() string {
+	return "Pulls all packages in pkg/ within their version constraint, the same as pkg:pull --all."
+}
+
+func (p PkgUpdate) Handle(c inter.Cli) inter.ExitCode {
+	config.App.Verbose = p.Verbose || p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVerbose = p.VeryVerbose || p.VeryVeryVerbose
+	config.App.VeryVeryVerbose = p.VeryVeryVerbose
+	root, err := getDirectoryOrCurrent(p.Directory)
+	if err != nil {
+		c.Error(err.Error())
+		return inter.Failure
+	}
+	config.Path.Root = root
+
+	if config.App.Verbose {
+		c.Info("Use directory: %s", root)
+	}
+	fmt.Println("\n\033[34mConfetti pkg:update\n\033[0m") // blue
+
+	return pullAllPackages(c)
+}
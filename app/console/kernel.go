@@ -20,7 +20,24 @@ func NewKernel(app inter.App) console.Kernel {
 			commands.Watch{},
 			commands.PkgPull{},
 			commands.PkgPush{},
+			commands.PkgUpdate{},
+			commands.PkgOutdated{},
+			commands.PkgList{},
+			commands.PkgStatus{},
+			commands.PkgRemove{},
+			commands.PkgCreate{},
+			commands.ComponentList{},
+			commands.ComponentShow{},
+			commands.ComponentDiff{},
 			commands.ContainerQuery{},
+			commands.ContainerLogs{},
+			commands.ContainerExec{},
+			commands.ContainerStart{},
+			commands.ContainerStop{},
+			commands.ContainerRestart{},
+			commands.CachePrune{},
+			commands.ConfigValidate{},
+			commands.ConfigShow{},
 		},
 
 		// This list includes custom flag.Getters, you can create custom
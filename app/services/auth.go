@@ -2,6 +2,8 @@ package services
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -26,32 +28,61 @@ type token struct {
 	ExpiresIn    int    `json:"expires_in"`
 }
 
-var currentToken *token
+// currentTokens holds a token per auth tenant, see authTokenFile.
+var currentTokens = map[string]*token{}
+var currentTokensMutex sync.Mutex
 var createOrUpdateAuthTokenFileMutex sync.Mutex
 
 func GetAccessToken(cli inter.Cli, env Environment) (string, error) {
-	if currentToken == nil {
+	if getCurrentToken(env) == nil {
 		err := tryTokenFromFile(cli, env)
 		if err != nil {
 			return "", err
 		}
 	}
-	return currentToken.AccessToken, nil
+	return getCurrentToken(env).AccessToken, nil
+}
+
+func getCurrentToken(env Environment) *token {
+	currentTokensMutex.Lock()
+	defer currentTokensMutex.Unlock()
+	return currentTokens[env.authTokenFile()]
+}
+
+func setCurrentToken(env Environment, t *token) {
+	currentTokensMutex.Lock()
+	defer currentTokensMutex.Unlock()
+	currentTokens[env.authTokenFile()] = t
+}
+
+// authTokenFile returns the file with the token of the auth tenant of the
+// environment. A token of one tenant is not valid for another tenant.
+func (e Environment) authTokenFile() string {
+	audience := e.Auth.Audience
+	if audience == "" {
+		audience = config.Auth0.Audience
+	}
+	if e.getAuthDomain() == config.Auth0.Domain && audience == config.Auth0.Audience {
+		// The default tenant keeps the file of before tenants were configurable
+		return "auth_token.json"
+	}
+	hash := sha256.Sum256([]byte(e.getAuthDomain() + "\n" + audience))
+	return "auth_token_" + hex.EncodeToString(hash[:])[:12] + ".json"
 }
 
 func tryTokenFromFile(cli inter.Cli, env Environment) error {
 	// Check if the file exists
-	_, err := os.Stat(path.Join(config.Path.Root, sharedResourcesDir, "auth_token.json"))
+	_, err := os.Stat(path.Join(config.Path.Root, sharedResourcesDir, env.authTokenFile()))
 	if os.IsNotExist(err) {
 		if config.App.Verbose {
 			fmt.Println("Token file does not exist, creating a new one...")
 		}
-		err := createOrUpdateAuthTokenFile(cli)
+		err := createOrUpdateAuthTokenFile(cli, env)
 		if err != nil {
 			return fmt.Errorf("unable to decode current token: %w", err)
 		}
 	}
-	err = useCurrentTokenFromFile()
+	err = useCurrentTokenFromFile(env)
 	if err != nil {
 		return fmt.Errorf("error using current token from file: %w", err)
 	}
@@ -62,7 +93,7 @@ func tryTokenFromFile(cli inter.Cli, env Environment) error {
 
 	// If the token is not valid, create a new one
 	if !valid {
-		err := createOrUpdateAuthTokenFile(cli)
+		err := createOrUpdateAuthTokenFile(cli, env)
 		if err != nil {
 			return fmt.Errorf("token from file not valid, unable to decode current token: %w", err)
 		}
@@ -75,10 +106,13 @@ const getRolesEndpoint = "/users/me"
 
 func currentTokenIsValid(cli inter.Cli, env Environment) (bool, error) {
 	// Create request
-	url := env.GetServiceUrl("confetti-cms/auth") + getRolesEndpoint
+	authUrl, err := env.GetServiceUrl("confetti-cms/auth")
+	if err != nil {
+		return false, err
+	}
 	req, err := http.NewRequest(
 		http.MethodGet,
-		url,
+		authUrl+getRolesEndpoint,
 		nil,
 	)
 	if err != nil {
@@ -86,7 +120,7 @@ func currentTokenIsValid(cli inter.Cli, env Environment) (bool, error) {
 	}
 	h := req.Header
 	h.Add("Content-Type", "application/json")
-	h.Add("Authorization", "Bearer "+currentToken.AccessToken)
+	h.Add("Authorization", "Bearer "+getCurrentToken(env).AccessToken)
 	req.Header = h
 	// Send request
 	client := &http.Client{}
@@ -111,25 +145,24 @@ func currentTokenIsValid(cli inter.Cli, env Environment) (bool, error) {
 	return true, nil
 }
 
-func useCurrentTokenFromFile() error {
+func useCurrentTokenFromFile(env Environment) error {
 	// The file exists, decode it
-	file, err := os.Open(path.Join(config.Path.Root, sharedResourcesDir, "auth_token.json"))
+	file, err := os.Open(path.Join(config.Path.Root, sharedResourcesDir, env.authTokenFile()))
 	if err != nil {
-		return fmt.Errorf("unable to open auth_token.json file: %w", err)
+		return fmt.Errorf("unable to open %s file: %w", env.authTokenFile(), err)
 	}
 	defer file.Close()
-	if currentToken == nil {
-		currentToken = &token{}
-	}
+	currentToken := &token{}
 	decoder := json.NewDecoder(file)
 	err = decoder.Decode(currentToken)
 	if err != nil {
 		return fmt.Errorf("unable to decode current token from file: %w", err)
 	}
+	setCurrentToken(env, currentToken)
 	return nil
 }
 
-func createOrUpdateAuthTokenFile(cli inter.Cli) error {
+func createOrUpdateAuthTokenFile(cli inter.Cli, env Environment) error {
 	createOrUpdateAuthTokenFileMutex.Lock()
 	defer createOrUpdateAuthTokenFileMutex.Unlock()
 	// The directory doesn't exist, create it
@@ -138,18 +171,18 @@ func createOrUpdateAuthTokenFile(cli inter.Cli) error {
 		return fmt.Errorf("unable to create directory: %w", err)
 	}
 	// Generate the token
-	err = FetchNewAccessToken(cli)
+	err = FetchNewAccessToken(cli, env)
 	if err != nil {
 		return fmt.Errorf("unable to fetch new access token: %w", err)
 	}
 	// Open file
-	file, err := os.OpenFile(path.Join(config.Path.Root, sharedResourcesDir, "auth_token.json"), os.O_RDWR|os.O_CREATE, os.ModePerm)
+	file, err := os.OpenFile(path.Join(config.Path.Root, sharedResourcesDir, env.authTokenFile()), os.O_RDWR|os.O_CREATE, os.ModePerm)
 	if err != nil {
 		return fmt.Errorf("unable to open or create file: %w", err)
 	}
 	defer file.Close()
 	// Save the token to the file
-	token, err := json.Marshal(currentToken)
+	token, err := json.Marshal(getCurrentToken(env))
 	if err != nil {
 		return fmt.Errorf("unable to marshal token: %w", err)
 	}
@@ -164,20 +197,20 @@ func createOrUpdateAuthTokenFile(cli inter.Cli) error {
 	return nil
 }
 
-func FetchNewAccessToken(cli inter.Cli) error {
-	token, err := getRefreshToken(cli)
+func FetchNewAccessToken(cli inter.Cli, env Environment) error {
+	token, err := getRefreshToken(cli, env)
 	if err != nil {
 		return err
 	}
-	currentToken = token
+	setCurrentToken(env, token)
 
 	return nil
 }
 
-func getRefreshToken(cli inter.Cli) (*token, error) {
-	url := "https://" + config.Auth0.Domain + "/oauth/device/code"
+func getRefreshToken(cli inter.Cli, env Environment) (*token, error) {
+	url := "https://" + env.getAuthDomain() + "/oauth/device/code"
 
-	payload := strings.NewReader("client_id=" + config.Auth0.ClientId + "&scope=offline_access openid&audience=" + config.Auth0.Audience)
+	payload := strings.NewReader("client_id=" + config.Auth0.ClientId + "&scope=offline_access openid&audience=" + env.getAuthAudience())
 
 	req, err := http.NewRequest("POST", url, payload)
 	if err != nil {
@@ -232,15 +265,15 @@ func getRefreshToken(cli inter.Cli) (*token, error) {
 	time.Sleep(5 * time.Second)
 
 	fmt.Print("\u001B[30;1m")
-	token, err := getTokenByDeviceCode(cli, content.DeviceCode)
+	token, err := getTokenByDeviceCode(cli, env, content.DeviceCode)
 	if err != nil {
 		return nil, err
 	}
 	return token, nil
 }
 
-func getTokenByDeviceCode(cli inter.Cli, deviceCode string) (*token, error) {
-	url := "https://" + config.Auth0.Domain + "/oauth/token"
+func getTokenByDeviceCode(cli inter.Cli, env Environment, deviceCode string) (*token, error) {
+	url := "https://" + env.getAuthDomain() + "/oauth/token"
 
 	payload := strings.NewReader("grant_type=urn%3Aietf%3Aparams%3Aoauth%3Agrant-type%3Adevice_code&device_code=" + deviceCode + "&client_id=" + config.Auth0.ClientId)
 
@@ -268,7 +301,7 @@ func getTokenByDeviceCode(cli inter.Cli, deviceCode string) (*token, error) {
 			fmt.Printf("\rRetry in%2d seconds ", i)
 			time.Sleep(time.Second)
 		}
-		return getTokenByDeviceCode(cli, deviceCode)
+		return getTokenByDeviceCode(cli, env, deviceCode)
 	}
 
 	// Clean entire screen
@@ -62,9 +62,9 @@ func StreamCommand(command string) (string, error) {
 
 	if err := cmd.Wait(); err != nil {
 		if stderr.Len() > 0 {
-			return out.String(), fmt.Errorf("command execution error: %v, stderr: %s", err, stderr.String())
+			return out.String(), fmt.Errorf("command execution error: %w, stderr: %s", err, stderr.String())
 		}
-		return out.String(), fmt.Errorf("command execution error: %v", err)
+		return out.String(), fmt.Errorf("command execution error: %w", err)
 	}
 
 	if stderr.Len() > 0 {
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	ComponentAdded   = "added"
+	ComponentRemoved = "removed"
+	ComponentChanged = "changed"
+)
+
+// ComponentChange is a change in the schema of a component. Without a
+// field, the component itself or its extends chain is changed.
+type ComponentChange struct {
+	Component string
+	Field     string
+	Kind      string
+	Before    string
+	After     string
+}
+
+// DiffParsedComponents compares the schema (extends chain and fields) of the
+// components. A component is missing on one side when it is added or removed.
+func DiffParsedComponents(before, after []ParsedComponent) []ComponentChange {
+	old := map[string]ParsedComponent{}
+	for _, component := range before {
+		old[component.Name] = component
+	}
+	changes := []ComponentChange{}
+	seen := map[string]bool{}
+	for _, component := range after {
+		seen[component.Name] = true
+		previous, found := old[component.Name]
+		if !found {
+			changes = append(changes, ComponentChange{Component: component.Name, Kind: ComponentAdded, After: component.File})
+			continue
+		}
+		changes = append(changes, DiffParsedComponent(previous, component)...)
+	}
+	for _, component := range before {
+		if !seen[component.Name] {
+			changes = append(changes, ComponentChange{Component: component.Name, Kind: ComponentRemoved, Before: component.File})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Component < changes[j].Component })
+	return changes
+}
+
+// DiffParsedComponent compares the schema of two versions of a component.
+func DiffParsedComponent(before, after ParsedComponent) []ComponentChange {
+	changes := []ComponentChange{}
+	beforeChain, afterChain := fmt.Sprint(before.Extends), fmt.Sprint(after.Extends)
+	if beforeChain != afterChain {
+		changes = append(changes, ComponentChange{Component: after.Name, Kind: ComponentChanged, Before: "extends " + beforeChain, After: "extends " + afterChain})
+	}
+	old := map[string]ParsedComponentField{}
+	for _, field := range before.Fields {
+		old[field.Key] = field
+	}
+	seen := map[string]bool{}
+	for _, field := range after.Fields {
+		seen[field.Key] = true
+		previous, found := old[field.Key]
+		switch {
+		case !found:
+			changes = append(changes, ComponentChange{Component: after.Name, Field: field.Key, Kind: ComponentAdded, After: fieldSchema(field)})
+		case fieldSchema(previous) != fieldSchema(field):
+			changes = append(changes, ComponentChange{Component: after.Name, Field: field.Key, Kind: ComponentChanged, Before: fieldSchema(previous), After: fieldSchema(field)})
+		}
+	}
+	for _, field := range before.Fields {
+		if !seen[field.Key] {
+			changes = append(changes, ComponentChange{Component: after.Name, Field: field.Key, Kind: ComponentRemoved, Before: fieldSchema(field)})
+		}
+	}
+	return changes
+}
+
+func fieldSchema(field ParsedComponentField) string {
+	decorations := field.DecorationsString()
+	if decorations == "" {
+		return field.Component
+	}
+	return field.Component + " " + decorations
+}
@@ -1,14 +1,22 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"src/config"
+	"strings"
 
 	"github.com/confetti-framework/framework/inter"
 )
 
+// vendorLockHashFile holds the hash of the composer.lock the vendor directory is installed with.
+const vendorLockHashFile = ".composer-lock-hash"
+
 func ComposerInstall(cli inter.Cli, env Environment) error {
 	// If config.Path.Root has composer.json but no vendor directory, install composer
 	_, err := os.Stat(filepath.Join(config.Path.Root, "composer.json"))
@@ -20,32 +28,156 @@ func ComposerInstall(cli inter.Cli, env Environment) error {
 	}
 	_, err = os.Stat(filepath.Join(config.Path.Root, "vendor"))
 	if !os.IsNotExist(err) {
-		if config.App.VeryVeryVerbose {
-			cli.Info("Vendor directory found in %s, skipping composer install", config.Path.Root)
+		stale, err := VendorIsStale()
+		if err != nil {
+			return err
+		}
+		if !stale {
+			if config.App.VeryVeryVerbose {
+				cli.Info("Vendor directory found in %s, skipping composer install", config.Path.Root)
+			}
+			return nil
+		}
+		if !cli.Confirm("composer.lock has changed since the vendor directory was installed. Do you want to run composer install?", true) {
+			return nil
+		}
+	}
+
+	return installVendor(cli, env)
+}
+
+// ComposerInstallIfStale installs the dependencies again when composer.lock
+// has changed (e.g. after a pull) while the vendor directory is still old.
+func ComposerInstallIfStale(cli inter.Cli, env Environment) error {
+	stale, err := VendorIsStale()
+	if err != nil || !stale {
+		return err
+	}
+	cli.Info("\ncomposer.lock has changed, installing the dependencies again")
+	return installVendor(cli, env)
+}
+
+func installVendor(cli inter.Cli, env Environment) error {
+	vendor := filepath.Join(config.Path.Root, "vendor")
+
+	// Reuse the vendor directory of another clone with the same composer.lock
+	key, err := VendorCacheKey()
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		restored, err := RestoreVendorFromCache(key)
+		if err != nil {
+			cli.Error(err.Error())
+		} else if restored {
+			cli.Info("Composer install (restored from cache)")
+			return RecordVendorLockHash()
 		}
-		return nil
 	}
 
 	cli.Info("Composer install")
 
-	cmd := fmt.Sprintf("cd %s && composer install --ignore-platform-reqs --no-interaction --no-progress --no-plugins", config.Path.Root)
+	_, composerErr := exec.LookPath("composer")
+	var installErr error
+	if composerErr == nil {
+		cmd := fmt.Sprintf("cd %s && composer install --ignore-platform-reqs --no-interaction --no-progress --no-plugins", config.Path.Root)
 
-	// Ignore the result because composer's warnings go to stderr.
-	_, _ = StreamCommand(cmd)
+		// Composer's warnings go to stderr, so only the exit code tells if the install failed.
+		_, err = StreamCommand(cmd)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			installErr = fmt.Errorf("composer install failed: %w", err)
+		}
+	}
 
 	// Check if the vendor directory is created.
 	// Ignore the error if the vendor directory was successfully created,
 	// as PHP may return many warnings to stderr, such as "Cannot load Xdebug - it was already loaded".
-	_, err = os.Stat(filepath.Join(config.Path.Root, "vendor"))
-	if os.IsNotExist(err) {
+	// Without composer, an existing vendor directory is outdated, so we replace it.
+	_, err = os.Stat(vendor)
+	if os.IsNotExist(err) || composerErr != nil {
 		if config.App.VeryVerbose {
-			println("Vendor directory not found in %s, downloading vendor directory from remote server", config.Path.Root)
+			fmt.Printf("Vendor directory not installed in %s, downloading vendor directory from remote server\n", config.Path.Root)
+		}
+		_ = os.RemoveAll(vendor)
+		parserUrl, err := env.GetServiceUrl("confetti-cms/parser")
+		if err != nil {
+			return err
 		}
-		err := DownloadZip(cli, env.GetServiceUrl("confetti-cms/parser")+"/vendor", config.Path.Root+"vendor", env)
+		err = DownloadZip(cli, parserUrl+"/vendor", config.Path.Root+"vendor", env)
 		if err != nil {
 			return err
 		}
+	} else if installErr != nil {
+		// The existing vendor directory doesn't match composer.lock, so it may not be marked fresh or cached
+		return installErr
 	}
 
+	err = RecordVendorLockHash()
+	if err != nil {
+		return err
+	}
+
+	if key != "" {
+		err = StoreVendorInCache(key)
+		if err != nil && config.App.Verbose {
+			cli.Info("Vendor directory not cached: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// ComposerLockHash returns the SHA-256 hash of composer.lock, or an
+// empty string when the project has no composer.lock.
+func ComposerLockHash() (string, error) {
+	content, err := os.ReadFile(filepath.Join(config.Path.Root, "composer.lock"))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read composer.lock: %w", err)
+	}
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// VendorIsStale reports whether composer.lock has changed since the vendor
+// directory was installed.
+func VendorIsStale() (bool, error) {
+	current, err := ComposerLockHash()
+	if err != nil || current == "" {
+		return false, err
+	}
+	installed, err := os.ReadFile(filepath.Join(config.Path.Root, "vendor", vendorLockHashFile))
+	if os.IsNotExist(err) {
+		// The vendor directory is installed before we kept track of it. We
+		// can't know if it's outdated, so from now on we compare with this version.
+		return false, RecordVendorLockHash()
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", vendorLockHashFile, err)
+	}
+	stale := strings.TrimSpace(string(installed)) != current
+	if stale && config.App.VeryVerbose {
+		fmt.Printf("Vendor directory is installed with composer.lock %s, current is %s\n", strings.TrimSpace(string(installed)), current)
+	}
+	return stale, nil
+}
+
+// RecordVendorLockHash marks the vendor directory as installed with the current composer.lock.
+func RecordVendorLockHash() error {
+	current, err := ComposerLockHash()
+	if err != nil || current == "" {
+		return err
+	}
+	vendor := filepath.Join(config.Path.Root, "vendor")
+	if _, err := os.Stat(vendor); os.IsNotExist(err) {
+		return nil
+	}
+	err = os.WriteFile(filepath.Join(vendor, vendorLockHashFile), []byte(current+"\n"), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", vendorLockHashFile, err)
+	}
 	return nil
 }
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/config"
+	"strings"
+)
+
+// jsonMember is a key with its value in a JSON object.
+type jsonMember struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// orderedJson is a JSON object that keeps the order of the keys, so we can
+// change composer.json without reordering it.
+type orderedJson []jsonMember
+
+func (o *orderedJson) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected an object, got %v", token)
+	}
+	*o = orderedJson{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		value := json.RawMessage{}
+		err = decoder.Decode(&value)
+		if err != nil {
+			return err
+		}
+		*o = append(*o, jsonMember{Key: token.(string), Value: value})
+	}
+	_, err = decoder.Token()
+	return err
+}
+
+func (o orderedJson) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, member := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(member.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(member.Value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (o orderedJson) get(key string) (json.RawMessage, bool) {
+	for _, member := range o {
+		if member.Key == key {
+			return member.Value, true
+		}
+	}
+	return nil, false
+}
+
+func (o *orderedJson) set(key string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	for i, member := range *o {
+		if member.Key == key {
+			(*o)[i].Value = raw
+			return nil
+		}
+	}
+	*o = append(*o, jsonMember{Key: key, Value: raw})
+	return nil
+}
+
+func (o *orderedJson) delete(key string) {
+	result := orderedJson{}
+	for _, member := range *o {
+		if member.Key != key {
+			result = append(result, member)
+		}
+	}
+	*o = result
+}
+
+func readComposerJson() (orderedJson, error) {
+	content, err := os.ReadFile(filepath.Join(config.Path.Root, "composer.json"))
+	if err != nil {
+		return nil, err
+	}
+	composer := orderedJson{}
+	err = json.Unmarshal(content, &composer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content in composer.json: %w", err)
+	}
+	return composer, nil
+}
+
+func writeComposerJson(composer orderedJson) error {
+	content, err := marshalComposerJson(composer)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(config.Path.Root, "composer.json"), content, 0644)
+}
+
+func marshalComposerJson(composer orderedJson) ([]byte, error) {
+	raw, err := json.Marshal(composer)
+	if err != nil {
+		return nil, err
+	}
+	// Composer indents with 4 spaces
+	content := bytes.Buffer{}
+	err = json.Indent(&content, raw, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	content.WriteByte('\n')
+	return content.Bytes(), nil
+}
+
+// ComposerRequires reports whether composer.json requires the package, e.g. by UpdateComposer.
+func ComposerRequires(pkg string) (bool, error) {
+	composer, err := readComposerJson()
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, key := range []string{"require", "require-dev"} {
+		raw, ok := composer.get(key)
+		if !ok {
+			continue
+		}
+		requires := map[string]any{}
+		if json.Unmarshal(raw, &requires) == nil {
+			if _, ok := requires[pkg]; ok {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// RemoveComposerAutoload removes the autoload entries and path repositories
+// from composer.json that point to pkg/<package>. It returns whether
+// composer.json is changed.
+func RemoveComposerAutoload(pkg string) (bool, error) {
+	composer, err := readComposerJson()
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	isPackagePath := func(path string) bool {
+		path = strings.TrimPrefix(path, "./")
+		return path == "pkg/"+pkg || strings.HasPrefix(path, "pkg/"+pkg+"/")
+	}
+
+	changed := false
+	for _, key := range []string{"autoload", "autoload-dev"} {
+		raw, ok := composer.get(key)
+		if !ok {
+			continue
+		}
+		autoload := orderedJson{}
+		if json.Unmarshal(raw, &autoload) != nil {
+			continue
+		}
+		result := orderedJson{}
+		for _, member := range autoload {
+			switch member.Key {
+			case "psr-4", "psr-0":
+				namespaces := orderedJson{}
+				if json.Unmarshal(member.Value, &namespaces) != nil {
+					break
+				}
+				kept := orderedJson{}
+				namespacesChanged := false
+				for _, namespace := range namespaces {
+					paths := autoloadPaths(namespace.Value)
+					remaining := []string{}
+					for _, path := range paths {
+						if !isPackagePath(path) {
+							remaining = append(remaining, path)
+						}
+					}
+					if len(remaining) == len(paths) {
+						kept = append(kept, namespace)
+						continue
+					}
+					namespacesChanged = true
+					if len(remaining) > 0 {
+						kept = append(kept, namespace)
+						_ = kept.set(namespace.Key, remaining)
+					}
+				}
+				if namespacesChanged {
+					changed = true
+					raw, _ := json.Marshal(kept)
+					member.Value = raw
+				}
+			case "classmap", "files":
+				paths := autoloadPaths(member.Value)
+				kept := []string{}
+				for _, path := range paths {
+					if !isPackagePath(path) {
+						kept = append(kept, path)
+					}
+				}
+				if len(kept) != len(paths) {
+					changed = true
+					member.Value, _ = json.Marshal(kept)
+				}
+			}
+			result = append(result, member)
+		}
+		_ = composer.set(key, result)
+	}
+
+	if raw, ok := composer.get("repositories"); ok {
+		repositories := []json.RawMessage{}
+		if json.Unmarshal(raw, &repositories) == nil {
+			kept := []json.RawMessage{}
+			for _, repository := range repositories {
+				path := struct {
+					Type string `json:"type"`
+					Url  string `json:"url"`
+				}{}
+				if json.Unmarshal(repository, &path) == nil && path.Type == "path" && isPackagePath(path.Url) {
+					continue
+				}
+				kept = append(kept, repository)
+			}
+			if len(kept) != len(repositories) {
+				changed = true
+				_ = composer.set("repositories", kept)
+			}
+		}
+	}
+
+	if !changed {
+		return false, nil
+	}
+	return true, writeComposerJson(composer)
+}
+
+// autoloadPaths returns the path or paths of an autoload entry.
+func autoloadPaths(raw json.RawMessage) []string {
+	path := ""
+	if json.Unmarshal(raw, &path) == nil {
+		return []string{path}
+	}
+	paths := []string{}
+	_ = json.Unmarshal(raw, &paths)
+	return paths
+}
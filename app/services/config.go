@@ -2,16 +2,13 @@ package services
 
 import (
 	"fmt"
+	"net/url"
 
-	"path/filepath"
 	"src/config"
 	"strings"
+	"sync"
 
 	"github.com/confetti-framework/framework/inter"
-
-	"os"
-
-	"github.com/titanous/json5"
 )
 
 const configFile = "config.json5"
@@ -20,25 +17,30 @@ type Hosts []string
 type Paths []string
 
 type ContainerConfig struct {
-	Name  string `json:"name"`
-	Hosts Hosts  `json:"hosts"`
-	Paths Paths  `json:"paths"`
+	Name     string `json:"name"`
+	Hosts    Hosts  `json:"hosts"`
+	Paths    Paths  `json:"paths"`
+	Protocol string `json:"protocol"`
+	Port     int    `json:"port"`
 }
 
-func (c *ContainerConfig) GetAllURLCombinations(defaultUri string) []string {
-	var combinations []string
-
-	for _, host := range c.Hosts {
-		uri := defaultUri
-		if len(c.Paths) > 0 {
-			// For now, we only support 1 path max
-			uri = strings.TrimLeft(c.Paths[0], "/")
-		}
-		url := host + "/" + uri
-		combinations = append(combinations, strings.TrimRight(url, "/"))
+// hostWithPort adds the configured port, unless the host already contains a port.
+func (c *ContainerConfig) hostWithPort(host string) string {
+	if c.Port == 0 || strings.Contains(host, ":") {
+		return host
 	}
+	return fmt.Sprintf("%s:%d", host, c.Port)
+}
 
-	return combinations
+// protocol returns the protocol of the container, by default https (or http when local).
+func (c *ContainerConfig) protocol(e Environment) string {
+	if c.Protocol != "" {
+		return c.Protocol
+	}
+	if e.Local {
+		return "http"
+	}
+	return "https"
 }
 
 const OrchestratorApiLocalhost = "http://api.confetti-cms.localhost/orchestrator"
@@ -48,20 +50,47 @@ type Options struct {
 	DevTools bool `json:"dev_tools"`
 }
 
+// AuthConfig overrides the Auth0 tenant (see config.Auth0) of an environment.
+type AuthConfig struct {
+	Domain   string `json:"domain"`
+	Audience string `json:"audience"`
+}
+
 type Environment struct {
-	Name       string            `json:"name"`
-	Local      bool              `json:"local"`
-	Options    Options           `json:"options"`
-	Containers []ContainerConfig `json:"containers"`
+	Name         string            `json:"name"`
+	Local        bool              `json:"local"`
+	Orchestrator string            `json:"orchestrator"`
+	Auth         AuthConfig        `json:"auth"`
+	Options      Options           `json:"options"`
+	Containers   []ContainerConfig `json:"containers"`
 }
 
+// GetOrchestratorApi returns the orchestrator of config.json5. Without an
+// orchestrator, the local or the default orchestrator of Confetti is used.
 func (e Environment) GetOrchestratorApi() string {
+	if e.Orchestrator != "" {
+		return strings.TrimRight(e.Orchestrator, "/")
+	}
 	if e.Local {
 		return OrchestratorApiLocalhost
 	}
 	return OrchestratorApiDefault
 }
 
+func (e Environment) getAuthDomain() string {
+	if e.Auth.Domain != "" {
+		return e.Auth.Domain
+	}
+	return config.Auth0.Domain
+}
+
+func (e Environment) getAuthAudience() string {
+	if e.Auth.Audience != "" {
+		return url.QueryEscape(e.Auth.Audience)
+	}
+	return config.Auth0.Audience
+}
+
 func (e Environment) GetAllHosts() []string {
 	hosts := []string{}
 	hostMap := make(map[string]bool)
@@ -93,12 +122,14 @@ func (e Environment) GetExplicitHosts() []string {
 	return hosts
 }
 
-func (e Environment) GetServiceUrl(service string) string {
+func (e Environment) GetServiceUrl(service string) (string, error) {
 	match := ContainerConfig{}
+	found := false
 	// Set default
 	for _, container := range e.Containers {
 		if container.Name == "" {
 			match = container
+			found = true
 			break
 		}
 	}
@@ -106,46 +137,103 @@ func (e Environment) GetServiceUrl(service string) string {
 	for _, container := range e.Containers {
 		if container.Name == service {
 			match = container
+			found = true
 			break
 		}
 	}
-	host := match.Hosts[0]
-	method := "https://"
-	if e.Local {
-		method = "http://"
+	if !found {
+		return "", fmt.Errorf("no container found for service %s in environment %s, add a container with this name or a default container (without a name) to %s", service, e.Name, configFile)
+	}
+	if len(match.Hosts) == 0 {
+		return "", fmt.Errorf("the container for service %s in environment %s has no hosts in %s", service, e.Name, configFile)
+	}
+	uri, err := e.getUriByAlias(match, service)
+	if err != nil {
+		return "", err
 	}
-	return method + host + getUriByAlias(match, service)
+	return match.protocol(e) + "://" + match.hostWithPort(match.Hosts[0]) + uri, nil
 }
 
-func getUriByAlias(cConfig ContainerConfig, service string) string {
-	uri := ""
-	if len(cConfig.Paths) > 0 {
-		// For now, we only support 1 path max
-		uri += "/" + strings.TrimLeft(cConfig.Paths[0], "/")
+// getUriByAlias returns the path to reach the service. Every path is a route:
+// a path with the name of the service (e.g. /api/confetti-cms/parser) is
+// used first, then a path with __SERVICE__ (e.g. /__ENV__/__SERVICE__).
+// When no path routes the service, the first path is used as prefix.
+func (e Environment) getUriByAlias(cConfig ContainerConfig, service string) (string, error) {
+	if len(cConfig.Paths) == 0 {
+		return "", nil
 	}
-	// Replace __SERVICE__ with the service name
-	uri = strings.ReplaceAll(uri, "__SERVICE__", service)
+	uris := []string{}
+	for _, path := range cConfig.Paths {
+		uri, err := e.resolvePathTemplate("/"+strings.TrimLeft(path, "/"), service)
+		if err != nil {
+			return "", err
+		}
+		uris = append(uris, strings.TrimRight(uri, "/"))
+	}
+	for i, path := range cConfig.Paths {
+		if !strings.Contains(path, "__SERVICE__") && containsPathSegments(uris[i], service) {
+			return uris[i], nil
+		}
+	}
+	for i, path := range cConfig.Paths {
+		if strings.Contains(path, "__SERVICE__") {
+			return uris[i], nil
+		}
+	}
+	return uris[0], nil
+}
 
-	return strings.TrimRight(uri, "/")
+// containsPathSegments reports whether the segments (e.g. confetti-cms/parser) are part of the path.
+func containsPathSegments(path string, segments string) bool {
+	return strings.Contains(path+"/", "/"+segments+"/")
 }
 
-type AppConfig struct {
-	Environments []Environment `json:"environments"`
+// resolvePathTemplate replaces the placeholders (see PathPlaceholders) in a path.
+func (e Environment) resolvePathTemplate(path string, service string) (string, error) {
+	// Replace __SERVICE__ with the service name
+	path = strings.ReplaceAll(path, "__SERVICE__", service)
+	path = strings.ReplaceAll(path, "__ENV__", e.Name)
+	if strings.Contains(path, "__ORG__") || strings.Contains(path, "__REPO__") {
+		repository, err := getProjectRepository()
+		if err != nil {
+			return "", fmt.Errorf("unable to resolve path %s: %w", path, err)
+		}
+		org, repo, _ := strings.Cut(repository, "/")
+		path = strings.ReplaceAll(path, "__ORG__", org)
+		path = strings.ReplaceAll(path, "__REPO__", repo)
+	}
+	return path, nil
 }
 
-func GetAppConfig() (AppConfig, error) {
-	aConfig := AppConfig{}
-	content, err := os.ReadFile(filepath.Join(config.Path.Root, configFile))
+var projectRepository = map[string]string{}
+var projectRepositoryMutex sync.Mutex
+
+// getProjectRepository returns the repository name (e.g. agency-name/website-name) of the project.
+func getProjectRepository() (string, error) {
+	projectRepositoryMutex.Lock()
+	defer projectRepositoryMutex.Unlock()
+	if repository, ok := projectRepository[config.Path.Root]; ok {
+		return repository, nil
+	}
+	repository, err := GetRepositoryName(config.Path.Root)
 	if err != nil {
-		return aConfig, fmt.Errorf("probably, you are not running this command in a Confetti project. Error: %s", err)
+		return "", err
 	}
+	projectRepository[config.Path.Root] = repository
+	return repository, nil
+}
 
-	err = json5.Unmarshal(content, &aConfig)
+type AppConfig struct {
+	Environments []Environment  `json:"environments"`
+	Packages     PackagesConfig `json:"packages"`
+}
+
+func GetAppConfig() (AppConfig, error) {
+	resolved, err := GetResolvedAppConfig()
 	if err != nil {
-		return aConfig, fmt.Errorf("invalid JSON5 content in %s: %s", configFile, err)
+		return AppConfig{}, err
 	}
-
-	return aConfig, nil
+	return decodeAppConfig(resolved)
 }
 
 func GetEnvironmentByInput(c inter.Cli, envName string) (Environment, error) {
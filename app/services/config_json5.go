@@ -0,0 +1,350 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// json5Node is a parsed JSON5 value that remembers where it is in the file.
+// The json5 package can only tell the byte offset of syntax errors, but to
+// validate config.json5 we need the position of every key and value.
+type json5Node struct {
+	Kind    string // object, array, string, number, boolean or null
+	Line    int
+	Column  int
+	Members []json5Member
+	Items   []*json5Node
+	String  string
+	Number  float64
+	Bool    bool
+}
+
+type json5Member struct {
+	Key    string
+	Line   int
+	Column int
+	Value  *json5Node
+}
+
+// Member returns the value of a key in an object, or nil when not found.
+func (n *json5Node) Member(key string) *json5Node {
+	if n == nil {
+		return nil
+	}
+	for _, member := range n.Members {
+		if member.Key == key {
+			return member.Value
+		}
+	}
+	return nil
+}
+
+// Find follows a path of keys (string) and indexes (int) and returns the deepest node found.
+func (n *json5Node) Find(path ...any) *json5Node {
+	current := n
+	for _, step := range path {
+		var next *json5Node
+		switch s := step.(type) {
+		case string:
+			next = current.Member(s)
+		case int:
+			if current.Kind == "array" && s < len(current.Items) {
+				next = current.Items[s]
+			}
+		}
+		if next == nil {
+			return current
+		}
+		current = next
+	}
+	return current
+}
+
+type json5Parser struct {
+	data   []rune
+	pos    int
+	line   int
+	column int
+}
+
+func parseJson5(content []byte) (*json5Node, error) {
+	p := &json5Parser{data: []rune(string(content)), line: 1, column: 1}
+	p.skipSpace()
+	node, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q after the end of the document", p.peek())
+	}
+	return node, nil
+}
+
+func (p *json5Parser) errorf(format string, args ...any) ConfigError {
+	return ConfigError{Line: p.line, Column: p.column, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *json5Parser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *json5Parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.data[p.pos]
+}
+
+func (p *json5Parser) next() rune {
+	r := p.data[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+		p.column = 1
+	} else {
+		p.column++
+	}
+	return r
+}
+
+func (p *json5Parser) skipSpace() {
+	for !p.eof() {
+		r := p.peek()
+		switch {
+		case unicode.IsSpace(r) || r == '\uFEFF':
+			p.next()
+		case r == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '/':
+			for !p.eof() && p.peek() != '\n' {
+				p.next()
+			}
+		case r == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '*':
+			p.next()
+			p.next()
+			for !p.eof() && !(p.peek() == '*' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '/') {
+				p.next()
+			}
+			if !p.eof() {
+				p.next()
+				p.next()
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *json5Parser) value() (*json5Node, error) {
+	if p.eof() {
+		return nil, p.errorf("unexpected end of the document")
+	}
+	node := &json5Node{Line: p.line, Column: p.column}
+	r := p.peek()
+	switch {
+	case r == '{':
+		return node, p.object(node)
+	case r == '[':
+		return node, p.array(node)
+	case r == '"' || r == '\'':
+		s, err := p.string()
+		node.Kind = "string"
+		node.String = s
+		return node, err
+	case r == '-' || r == '+' || r == '.' || unicode.IsDigit(r):
+		return node, p.number(node)
+	}
+	word := p.identifier()
+	switch word {
+	case "true", "false":
+		node.Kind = "boolean"
+		node.Bool = word == "true"
+	case "null":
+		node.Kind = "null"
+	case "Infinity", "NaN":
+		node.Kind = "number"
+	default:
+		if word == "" {
+			return nil, p.errorf("unexpected %q", r)
+		}
+		return nil, ConfigError{Line: node.Line, Column: node.Column, Message: fmt.Sprintf("unexpected %q, strings need quotes", word)}
+	}
+	return node, nil
+}
+
+func (p *json5Parser) object(node *json5Node) error {
+	node.Kind = "object"
+	p.next() // {
+	for {
+		p.skipSpace()
+		if p.peek() == '}' {
+			p.next()
+			return nil
+		}
+		member := json5Member{Line: p.line, Column: p.column}
+		var err error
+		if p.peek() == '"' || p.peek() == '\'' {
+			member.Key, err = p.string()
+			if err != nil {
+				return err
+			}
+		} else {
+			member.Key = p.identifier()
+			if member.Key == "" {
+				if p.eof() {
+					return p.errorf("unexpected end of the document, missing '}'")
+				}
+				return p.errorf("unexpected %q, expected a key", p.peek())
+			}
+		}
+		p.skipSpace()
+		if p.peek() != ':' {
+			return p.errorf("expected ':' after key %q", member.Key)
+		}
+		p.next()
+		p.skipSpace()
+		member.Value, err = p.value()
+		if err != nil {
+			return err
+		}
+		node.Members = append(node.Members, member)
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.next()
+		case '}':
+		default:
+			if p.eof() {
+				return p.errorf("unexpected end of the document, missing '}'")
+			}
+			return p.errorf("expected ',' or '}' after the value of %q", member.Key)
+		}
+	}
+}
+
+func (p *json5Parser) array(node *json5Node) error {
+	node.Kind = "array"
+	p.next() // [
+	for {
+		p.skipSpace()
+		if p.peek() == ']' {
+			p.next()
+			return nil
+		}
+		item, err := p.value()
+		if err != nil {
+			return err
+		}
+		node.Items = append(node.Items, item)
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.next()
+		case ']':
+		default:
+			if p.eof() {
+				return p.errorf("unexpected end of the document, missing ']'")
+			}
+			return p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (p *json5Parser) string() (string, error) {
+	quote := p.next()
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		r := p.next()
+		switch {
+		case r == quote:
+			return b.String(), nil
+		case r == '\n':
+			return "", p.errorf("unterminated string")
+		case r == '\\':
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+			escaped := p.next()
+			switch escaped {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			case 'r':
+				b.WriteRune('\r')
+			case 'b':
+				b.WriteRune('\b')
+			case 'f':
+				b.WriteRune('\f')
+			case '0':
+				b.WriteRune(0)
+			case '\n':
+				// Line continuation
+			case 'u':
+				if p.pos+4 > len(p.data) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(string(p.data[p.pos:p.pos+4]), 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				for range 4 {
+					p.next()
+				}
+				b.WriteRune(rune(code))
+			default:
+				b.WriteRune(escaped)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+func (p *json5Parser) number(node *json5Node) error {
+	node.Kind = "number"
+	start := p.pos
+	for !p.eof() {
+		r := p.peek()
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '+' || r == '-' {
+			p.next()
+			continue
+		}
+		break
+	}
+	raw := strings.TrimPrefix(string(p.data[start:p.pos]), "+")
+	if strings.HasSuffix(raw, "Infinity") || strings.HasSuffix(raw, "NaN") {
+		return nil
+	}
+	if strings.HasPrefix(strings.TrimPrefix(raw, "-"), "0x") || strings.HasPrefix(strings.TrimPrefix(raw, "-"), "0X") {
+		v, err := strconv.ParseInt(raw, 0, 64)
+		if err != nil {
+			return ConfigError{Line: node.Line, Column: node.Column, Message: fmt.Sprintf("invalid number %q", raw)}
+		}
+		node.Number = float64(v)
+		return nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return ConfigError{Line: node.Line, Column: node.Column, Message: fmt.Sprintf("invalid number %q", raw)}
+	}
+	node.Number = v
+	return nil
+}
+
+func (p *json5Parser) identifier() string {
+	start := p.pos
+	for !p.eof() {
+		r := p.peek()
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$' {
+			p.next()
+			continue
+		}
+		break
+	}
+	return string(p.data[start:p.pos])
+}
@@ -0,0 +1,396 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"src/config"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/titanous/json5"
+)
+
+// localConfigFile is merged on top of config.json5. It is meant for personal
+// settings, so it should be ignored by git.
+const localConfigFile = "config.local.json5"
+
+// GetResolvedAppConfig returns config.json5 merged with config.local.json5,
+// with the `extends` of the environments and the ${VAR} variables resolved.
+func GetResolvedAppConfig() (map[string]any, error) {
+	content, localContent, err := readAppConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+	resolved, _, err := resolveAppConfig(content, localContent)
+	if err != nil {
+		if _, ok := err.(ConfigErrors); ok {
+			return nil, fmt.Errorf("invalid %s, run `conf config:validate` for more information:\n%w", configFile, err)
+		}
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// readAppConfigFiles returns the content of config.json5 and config.local.json5.
+// The local content is nil when there is no local file.
+func readAppConfigFiles() ([]byte, []byte, error) {
+	content, err := os.ReadFile(filepath.Join(config.Path.Root, configFile))
+	if err != nil {
+		return nil, nil, fmt.Errorf("probably, you are not running this command in a Confetti project. Error: %s", err)
+	}
+	localContent, err := os.ReadFile(filepath.Join(config.Path.Root, localConfigFile))
+	if os.IsNotExist(err) {
+		return content, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read %s: %w", localConfigFile, err)
+	}
+	if config.App.VeryVerbose {
+		fmt.Printf("Merge %s on top of %s\n", localConfigFile, configFile)
+	}
+	return content, localContent, nil
+}
+
+func resolveAppConfig(content, localContent []byte) (map[string]any, *json5Node, error) {
+	node, err := validateConfigContent(content, configFile, false)
+	if err != nil {
+		return nil, node, err
+	}
+	resolved := map[string]any{}
+	err = json5.Unmarshal(content, &resolved)
+	if err != nil {
+		return nil, node, fmt.Errorf("invalid JSON5 content in %s: %s", configFile, err)
+	}
+
+	if localContent != nil {
+		// The local file only contains the values to override, so nothing is required
+		_, err := validateConfigContent(localContent, localConfigFile, true)
+		if err != nil {
+			return nil, node, err
+		}
+		local := map[string]any{}
+		err = json5.Unmarshal(localContent, &local)
+		if err != nil {
+			return nil, node, fmt.Errorf("invalid JSON5 content in %s: %s", localConfigFile, err)
+		}
+		resolved = mergeConfigValues(resolved, local).(map[string]any)
+	}
+
+	err = resolveExtends(resolved, node)
+	if err != nil {
+		return nil, node, err
+	}
+
+	missing := map[string]bool{}
+	resolved = interpolateConfigVariables(resolved, missing).(map[string]any)
+	if len(missing) > 0 {
+		errs := ConfigErrors{}
+		for _, name := range sortedKeys(missing) {
+			configErr := ConfigError{Line: 1, Column: 1, Message: fmt.Sprintf("variable ${%s} is not set in the environment or in .env", name)}
+			if !setPositionOf(&configErr, content, "${"+name) {
+				configErr.File = localConfigFile
+				setPositionOf(&configErr, localContent, "${"+name)
+			}
+			errs = append(errs, configErr)
+		}
+		return nil, node, errs
+	}
+	return resolved, node, nil
+}
+
+func validateConfigContent(content []byte, file string, partial bool) (*json5Node, error) {
+	node, err := parseJson5(content)
+	if err != nil {
+		if configErr, ok := err.(ConfigError); ok {
+			configErr.File = file
+			return nil, ConfigErrors{configErr}
+		}
+		return nil, err
+	}
+	interpolateConfigNode(node)
+	schema := map[string]any{}
+	err = json.Unmarshal(config.Embed.ConfigSchema, &schema)
+	if err != nil {
+		return node, fmt.Errorf("invalid JSON schema for %s: %w", configFile, err)
+	}
+	errs := validateSchema(node, schema, schema, "", partial)
+	if len(errs) > 0 {
+		for i := range errs {
+			errs[i].File = file
+		}
+		return node, ConfigErrors(errs)
+	}
+	return node, nil
+}
+
+func decodeAppConfig(resolved map[string]any) (AppConfig, error) {
+	aConfig := AppConfig{}
+	raw, err := json.Marshal(resolved)
+	if err != nil {
+		return aConfig, err
+	}
+	err = json.Unmarshal(raw, &aConfig)
+	if err != nil {
+		return aConfig, fmt.Errorf("invalid content in %s: %w", configFile, err)
+	}
+	return aConfig, nil
+}
+
+// namedConfigLists are the lists of objects that are merged by their name.
+var namedConfigLists = []string{"environments", "containers"}
+
+// mergeConfigValues deep merges override on top of base. Lists of objects
+// (see namedConfigLists) are merged by their name, other lists are replaced.
+func mergeConfigValues(base, override any) any {
+	switch o := override.(type) {
+	case map[string]any:
+		b, ok := base.(map[string]any)
+		if !ok {
+			return o
+		}
+		result := map[string]any{}
+		for key, value := range b {
+			result[key] = value
+		}
+		for key, value := range o {
+			if _, isList := value.([]any); isList && !ContainsString(namedConfigLists, key) {
+				result[key] = value
+				continue
+			}
+			result[key] = mergeConfigValues(b[key], value)
+		}
+		return result
+	case []any:
+		b, ok := base.([]any)
+		if !ok || !allNamedObjects(b) || !allNamedObjects(o) {
+			return o
+		}
+		result := []any{}
+		for _, item := range b {
+			result = append(result, withDefaultName(item.(map[string]any)))
+		}
+		for _, item := range o {
+			item := withDefaultName(item.(map[string]any))
+			merged := false
+			for i, existing := range result {
+				if existing.(map[string]any)["name"] == item["name"] {
+					result[i] = mergeConfigValues(existing, item)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				result = append(result, item)
+			}
+		}
+		return result
+	}
+	return override
+}
+
+func allNamedObjects(items []any) bool {
+	for _, item := range items {
+		if _, ok := item.(map[string]any); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// withDefaultName returns a copy of the object with an empty name when the
+// name is missing, because a missing name is the default container.
+func withDefaultName(item map[string]any) map[string]any {
+	result := map[string]any{"name": ""}
+	for key, value := range item {
+		result[key] = value
+	}
+	return result
+}
+
+// resolveExtends merges every environment on top of the environment it extends.
+func resolveExtends(resolved map[string]any, node *json5Node) error {
+	environments, _ := resolved["environments"].([]any)
+	byName := map[string]map[string]any{}
+	for _, environment := range environments {
+		env, ok := environment.(map[string]any)
+		if _, exists := byName[fmt.Sprint(env["name"])]; ok && !exists {
+			byName[fmt.Sprint(env["name"])] = env
+		}
+	}
+
+	done := map[string]map[string]any{}
+	var resolve func(name string, chain []string) (map[string]any, error)
+	resolve = func(name string, chain []string) (map[string]any, error) {
+		if env, ok := done[name]; ok {
+			return env, nil
+		}
+		env := byName[name]
+		parentName, ok := env["extends"].(string)
+		if !ok {
+			done[name] = env
+			return env, nil
+		}
+		i := indexOfEnvironment(environments, name)
+		position := node.Find("environments", i, "extends")
+		if ContainsString(chain, parentName) {
+			return nil, ConfigError{Line: position.Line, Column: position.Column, Path: configPath("environments", i, "extends"), Message: fmt.Sprintf("environments extend each other in a loop: %s -> %s", strings.Join(chain, " -> "), parentName)}
+		}
+		if _, ok := byName[parentName]; !ok {
+			return nil, ConfigError{Line: position.Line, Column: position.Column, Path: configPath("environments", i, "extends"), Message: fmt.Sprintf("environment %q extends %q, but that environment does not exist", name, parentName)}
+		}
+		parent, err := resolve(parentName, append(chain, parentName))
+		if err != nil {
+			return nil, err
+		}
+		merged := mergeConfigValues(parent, env).(map[string]any)
+		merged["name"] = name
+		delete(merged, "extends")
+		done[name] = merged
+		return merged, nil
+	}
+
+	for i, environment := range environments {
+		env, ok := environment.(map[string]any)
+		if _, extends := env["extends"]; !ok || !extends {
+			continue
+		}
+		name := fmt.Sprint(env["name"])
+		resolvedEnv, err := resolve(name, []string{name})
+		if err != nil {
+			return ConfigErrors{err.(ConfigError)}
+		}
+		environments[i] = resolvedEnv
+	}
+	return nil
+}
+
+func indexOfEnvironment(environments []any, name string) int {
+	for i, environment := range environments {
+		if env, ok := environment.(map[string]any); ok && fmt.Sprint(env["name"]) == name {
+			return i
+		}
+	}
+	return 0
+}
+
+var configVariableRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// interpolateConfigVariables replaces ${VAR} and ${VAR:-default} in all
+// strings. Variables are read from the environment and from .env in the
+// root of the project. Variables that can't be found are added to missing.
+// The value itself is not changed, the result is a copy.
+func interpolateConfigVariables(value any, missing map[string]bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := map[string]any{}
+		for key, item := range v {
+			result[key] = interpolateConfigVariables(item, missing)
+		}
+		return result
+	case []any:
+		result := []any{}
+		for _, item := range v {
+			result = append(result, interpolateConfigVariables(item, missing))
+		}
+		return result
+	case string:
+		return interpolateConfigString(v, missing)
+	}
+	return value
+}
+
+var configNumberRegex = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// interpolateConfigString replaces the variables in a string. A value that is
+// only a variable (e.g. "${PORT}") becomes a number or a boolean when the
+// variable contains one, so it can be used for ports and flags.
+func interpolateConfigString(value string, missing map[string]bool) any {
+	result := configVariableRegex.ReplaceAllStringFunc(value, func(match string) string {
+		parts := configVariableRegex.FindStringSubmatch(match)
+		if result, ok := lookupConfigVariable(parts[1]); ok {
+			return result
+		}
+		if parts[2] != "" {
+			return parts[3]
+		}
+		missing[parts[1]] = true
+		return match
+	})
+	if result == value || configVariableRegex.FindString(value) != value {
+		return result
+	}
+	if result == "true" || result == "false" {
+		return result == "true"
+	}
+	if configNumberRegex.MatchString(result) {
+		number, err := strconv.ParseFloat(result, 64)
+		if err == nil {
+			return number
+		}
+	}
+	return result
+}
+
+// interpolateConfigNode replaces the variables in the parsed content, so the
+// schema checks the values and the errors still point to the right position.
+func interpolateConfigNode(node *json5Node) {
+	switch node.Kind {
+	case "object":
+		for _, member := range node.Members {
+			interpolateConfigNode(member.Value)
+		}
+	case "array":
+		for _, item := range node.Items {
+			interpolateConfigNode(item)
+		}
+	case "string":
+		// Missing variables are reported after the schema is checked
+		switch v := interpolateConfigString(node.String, map[string]bool{}).(type) {
+		case float64:
+			node.Kind, node.Number = "number", v
+		case bool:
+			node.Kind, node.Bool = "boolean", v
+		case string:
+			node.String = v
+		}
+	}
+}
+
+func lookupConfigVariable(name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	// The .env file of the current directory is already loaded by the
+	// framework, but the project can be in another directory.
+	values, err := godotenv.Read(filepath.Join(config.Path.Root, ".env"))
+	if err != nil {
+		return "", false
+	}
+	value, ok := values[name]
+	return value, ok
+}
+
+// setPositionOf points the error to the first occurrence of search in content.
+func setPositionOf(configErr *ConfigError, content []byte, search string) bool {
+	before, _, found := strings.Cut(string(content), search)
+	if !found {
+		return false
+	}
+	configErr.Line = strings.Count(before, "\n") + 1
+	configErr.Column = len([]rune(before[strings.LastIndex(before, "\n")+1:])) + 1
+	return true
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
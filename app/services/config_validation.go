@@ -0,0 +1,333 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ConfigError points to the place in config.json5 (or config.local.json5) that is invalid.
+type ConfigError struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+func (e ConfigError) Error() string {
+	file := e.File
+	if file == "" {
+		file = configFile
+	}
+	if e.Path == "" {
+		return fmt.Sprintf("%s:%d:%d: %s", file, e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", file, e.Line, e.Column, e.Path, e.Message)
+}
+
+type ConfigErrors []ConfigError
+
+func (e ConfigErrors) Error() string {
+	lines := []string{}
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PathPlaceholders can be used in the paths of a container.
+var PathPlaceholders = []string{"__SERVICE__", "__ORG__", "__REPO__", "__ENV__"}
+
+// ValidateAppConfig checks the content of config.json5 (and the optional
+// config.local.json5) against the JSON schema and against the rules that a
+// schema can't express. The rules are checked after the files are merged.
+func ValidateAppConfig(content []byte, localContent []byte) []ConfigError {
+	resolved, node, err := resolveAppConfig(content, localContent)
+	if err == nil {
+		var aConfig AppConfig
+		aConfig, err = decodeAppConfig(resolved)
+		if err == nil {
+			return validateAppConfigRules(aConfig, node)
+		}
+	}
+	if errs, ok := err.(ConfigErrors); ok {
+		return errs
+	}
+	return []ConfigError{{Line: 1, Column: 1, Message: err.Error()}}
+}
+
+// validateSchema checks a node against the JSON schema. With partial, required
+// keys are not checked, because config.local.json5 only contains overrides.
+func validateSchema(node *json5Node, schema map[string]any, root map[string]any, path string, partial bool) []ConfigError {
+	errs := []ConfigError{}
+	fail := func(n *json5Node, p string, format string, args ...any) {
+		errs = append(errs, ConfigError{Line: n.Line, Column: n.Column, Path: p, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if node.Kind == "string" && configVariableRegex.MatchString(node.String) {
+		// The variable is not set, that is reported after the schema is checked
+		return errs
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/$defs/")
+		defs, _ := root["$defs"].(map[string]any)
+		def, ok := defs[name].(map[string]any)
+		if !ok {
+			fail(node, path, "unknown schema reference %s", ref)
+			return errs
+		}
+		errs = append(errs, validateSchema(node, def, root, path, partial)...)
+	}
+
+	if expected, ok := schema["type"]; ok {
+		types := []string{}
+		switch t := expected.(type) {
+		case string:
+			types = append(types, t)
+		case []any:
+			for _, v := range t {
+				types = append(types, fmt.Sprint(v))
+			}
+		}
+		if !nodeHasType(node, types) {
+			fail(node, path, "expected %s, got %s", strings.Join(types, " or "), node.Kind)
+			return errs
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		options := []string{}
+		for _, v := range enum {
+			options = append(options, fmt.Sprint(v))
+			if node.Kind == "string" && node.String == v {
+				found = true
+			}
+		}
+		if !found {
+			fail(node, path, "expected one of %s", strings.Join(options, ", "))
+		}
+	}
+
+	switch node.Kind {
+	case "string":
+		if minLength, ok := schema["minLength"].(float64); ok && float64(len(node.String)) < minLength {
+			if minLength == 1 {
+				fail(node, path, "may not be empty")
+			} else {
+				fail(node, path, "must be at least %d characters", int(minLength))
+			}
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err == nil && !re.MatchString(node.String) {
+				fail(node, path, "%q does not match the pattern %s", node.String, pattern)
+			}
+		}
+	case "number":
+		if minimum, ok := schema["minimum"].(float64); ok && node.Number < minimum {
+			fail(node, path, "must be at least %v", minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && node.Number > maximum {
+			fail(node, path, "must be at most %v", maximum)
+		}
+	case "array":
+		if minItems, ok := schema["minItems"].(float64); ok && float64(len(node.Items)) < minItems {
+			fail(node, path, "must contain at least %d item(s)", int(minItems))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range node.Items {
+				errs = append(errs, validateSchema(item, items, root, fmt.Sprintf("%s[%d]", path, i), partial)...)
+			}
+		}
+	case "object":
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok && !partial {
+			for _, key := range required {
+				if node.Member(fmt.Sprint(key)) == nil {
+					fail(node, path, "missing required key %q", key)
+				}
+			}
+		}
+		keys := []string{}
+		for key := range properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, member := range node.Members {
+			memberPath := strings.TrimPrefix(path+"."+member.Key, ".")
+			if property, ok := properties[member.Key].(map[string]any); ok {
+				errs = append(errs, validateSchema(member.Value, property, root, memberPath, partial)...)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if additional {
+					continue
+				}
+			case map[string]any:
+				errs = append(errs, validateSchema(member.Value, additional, root, memberPath, partial)...)
+				continue
+			default:
+				continue
+			}
+			keyNode := &json5Node{Line: member.Line, Column: member.Column}
+			if suggestion := closestWord(member.Key, keys); suggestion != "" {
+				fail(keyNode, path, "unknown key %q, did you mean %q?", member.Key, suggestion)
+			} else {
+				fail(keyNode, path, "unknown key %q, allowed keys are %s", member.Key, strings.Join(keys, ", "))
+			}
+		}
+	}
+	return errs
+}
+
+func nodeHasType(node *json5Node, types []string) bool {
+	for _, t := range types {
+		if t == node.Kind {
+			return true
+		}
+		if t == "integer" && node.Kind == "number" && node.Number == math.Trunc(node.Number) {
+			return true
+		}
+	}
+	return false
+}
+
+var hostnameRegex = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(:[0-9]{1,5})?$`)
+var pathRegex = regexp.MustCompile(`^/?[a-zA-Z0-9_.~/-]*$`)
+var placeholderRegex = regexp.MustCompile(`__[A-Z]+__`)
+
+// validateAppConfigRules checks the rules that are not part of the JSON schema.
+func validateAppConfigRules(aConfig AppConfig, node *json5Node) []ConfigError {
+	errs := []ConfigError{}
+	fail := func(format string, args []any, path ...any) {
+		n := node.Find(path...)
+		errs = append(errs, ConfigError{Line: n.Line, Column: n.Column, Path: configPath(path...), Message: fmt.Sprintf(format, args...)})
+	}
+
+	envNames := map[string]int{}
+	for e, environment := range aConfig.Environments {
+		if first, ok := envNames[environment.Name]; ok {
+			fail("environment name %q is already used by environments[%d]", []any{environment.Name, first}, "environments", e, "name")
+		} else {
+			envNames[environment.Name] = e
+		}
+
+		defaults := 0
+		containerNames := map[string]int{}
+		for c, container := range environment.Containers {
+			if container.Name == "" {
+				defaults++
+			} else if first, ok := containerNames[container.Name]; ok {
+				fail("container name %q is already used by containers[%d]", []any{container.Name, first}, "environments", e, "containers", c, "name")
+			} else {
+				containerNames[container.Name] = c
+			}
+			if len(container.Hosts) == 0 {
+				fail("container has no hosts", nil, "environments", e, "containers", c)
+			}
+			for h, host := range container.Hosts {
+				if !hostnameRegex.MatchString(host) {
+					fail("%q is not a valid hostname, use a hostname without protocol and path (e.g. example.com or localhost:8080)", []any{host}, "environments", e, "containers", c, "hosts", h)
+				}
+			}
+			for p, path := range container.Paths {
+				if !pathRegex.MatchString(placeholderRegex.ReplaceAllString(path, "")) {
+					fail("%q is not a valid path template", []any{path}, "environments", e, "containers", c, "paths", p)
+				}
+				for _, placeholder := range placeholderRegex.FindAllString(path, -1) {
+					if !ContainsString(PathPlaceholders, placeholder) {
+						fail("unknown placeholder %s in path, available placeholders are %s", []any{placeholder, strings.Join(PathPlaceholders, ", ")}, "environments", e, "containers", c, "paths", p)
+					}
+				}
+			}
+		}
+		if defaults == 0 {
+			fail("environment %q has no default container, add a container without a name", []any{environment.Name}, "environments", e, "containers")
+		}
+	}
+
+	for s, source := range aConfig.Packages.Sources {
+		if _, err := path.Match(source.Match, ""); err != nil {
+			fail("%q is not a valid pattern, use a pattern like acme/*", []any{source.Match}, "packages", "sources", s, "match")
+		}
+		if source.Url == "" {
+			continue
+		}
+		if source.Protocol != "" || source.Host != "" {
+			fail("use a url or a protocol and host, not both", nil, "packages", "sources", s, "url")
+		}
+		if urlHasCredentials(source.Url) {
+			fail("don't put credentials in the url, use credential_helper or a git credential helper instead", nil, "packages", "sources", s, "url")
+		}
+		for _, placeholder := range placeholderRegex.FindAllString(source.Url, -1) {
+			if !ContainsString(PackagePlaceholders, placeholder) {
+				fail("unknown placeholder %s in url, available placeholders are %s", []any{placeholder, strings.Join(PackagePlaceholders, ", ")}, "packages", "sources", s, "url")
+			}
+		}
+	}
+	return errs
+}
+
+func configPath(path ...any) string {
+	result := ""
+	for _, step := range path {
+		switch s := step.(type) {
+		case string:
+			result += "." + s
+		case int:
+			result += fmt.Sprintf("[%d]", s)
+		}
+	}
+	return strings.TrimPrefix(result, ".")
+}
+
+// ContainsString reports whether search is one of the items in list.
+func ContainsString(list []string, search string) bool {
+	for _, item := range list {
+		if item == search {
+			return true
+		}
+	}
+	return false
+}
+
+// closestWord returns the word that is probably meant by a typo.
+func closestWord(word string, words []string) string {
+	best := ""
+	bestDistance := 3
+	for _, candidate := range words {
+		distance := levenshtein(strings.ToLower(word), strings.ToLower(candidate))
+		if distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
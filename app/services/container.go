@@ -1,150 +1,60 @@
 package services
 
 import (
-	"encoding/json"
-	"net/http"
-	"net/url"
-	"time"
-
-	"github.com/confetti-framework/framework/inter"
+	"src/app/services/orchestrator"
+	"strings"
 )
 
-type ContainerInformation struct {
-	ID                   string                 `json:"id"`
-	Locator              string                 `json:"locator"`
-	SourceOrganization   string                 `json:"source_organization"`
-	SourceRepository     string                 `json:"source_repository"`
-	UmbrellaOrganization string                 `json:"umbrella_organization"`
-	UmbrellaRepository   string                 `json:"umbrella_repository"`
-	Name                 string                 `json:"name"`
-	Target               string                 `json:"target"`
-	Status               string                 `json:"status"`
-	Ports                []uint                 `json:"ports"`
-	NetworkName          string                 `json:"network_name"`
-	Environment          EnvironmentInformation `json:"environment"`
-}
-
-type EnvironmentInformation struct {
-	Name  string `json:"name"`
-	Stage string `json:"stage"`
+// ContainerFilter narrows down the containers of the orchestrator. An empty
+// list matches everything, otherwise one of the values has to match.
+type ContainerFilter struct {
+	Statuses []string
+	Names    []string
+	Stages   []string
+	Locators []string
 }
 
-type QueryContainerOptions struct {
-	Environment          string `json:"environment"`
-	UmbrellaOrganization string `json:"umbrella_organization"`
-	UmbrellaRepository   string `json:"umbrella_repository"`
+func FilterContainers(containers []orchestrator.ContainerInformation, filter ContainerFilter) []orchestrator.ContainerInformation {
+	filtered := []orchestrator.ContainerInformation{}
+	for _, container := range containers {
+		if !matchesAny(container.Status, filter.Statuses) ||
+			!matchesAny(container.Name, filter.Names) ||
+			!matchesAny(container.Environment.Stage, filter.Stages) ||
+			!matchesAny(container.Locator, filter.Locators) {
+			continue
+		}
+		filtered = append(filtered, container)
+	}
+	return filtered
 }
 
-func GetContainers(cli inter.Cli, runningEnv Environment, options QueryContainerOptions) ([]ContainerInformation, error) {
-	u, err := url.Parse(GetOrchestratorContainerListUrl(runningEnv.Local))
-	if err != nil {
-		return nil, err
+func matchesAny(value string, options []string) bool {
+	if len(options) == 0 {
+		return true
 	}
-	q := u.Query()
-	if options.Environment != "" {
-		q.Set("environment", options.Environment)
+	for _, option := range options {
+		if strings.EqualFold(strings.TrimSpace(option), value) {
+			return true
+		}
 	}
-	if options.UmbrellaOrganization != "" {
-		q.Set("umbrella_organization", options.UmbrellaOrganization)
-	}
-	if options.UmbrellaRepository != "" {
-		q.Set("umbrella_repository", options.UmbrellaRepository)
-	}
-	u.RawQuery = q.Encode()
+	return false
+}
 
-	resp, err := Send(cli, u.String(), nil, http.MethodGet, runningEnv, "", 30*time.Second)
-	if err != nil {
-		return nil, err
+// MalformedContainerReason explains why the orchestrator returned a container
+// that can't be used, or returns an empty string for a valid container.
+func MalformedContainerReason(container orchestrator.ContainerInformation) string {
+	missing := []string{}
+	if container.ID == "" {
+		missing = append(missing, "id")
 	}
-
-	type responseData struct {
-		Data []ContainerInformation `json:"data"`
+	if container.UmbrellaOrganization == "" {
+		missing = append(missing, "umbrella organization")
 	}
-	var result responseData
-	err = json.Unmarshal([]byte(resp), &result)
-	if err != nil {
-		return nil, err
+	if container.UmbrellaRepository == "" {
+		missing = append(missing, "umbrella repository")
 	}
-
-	// For demo, we add a dummy data with unique locators
-	result.Data = append(result.Data,
-		// DEV
-		ContainerInformation{
-			ID:                   "dev-website-id",
-			Locator:              "flip-agency/potlodenshop/website?env=dev",
-			SourceOrganization:   "flip-agency",
-			SourceRepository:     "potlodenshop",
-			UmbrellaOrganization: "flip-agency",
-			UmbrellaRepository:   "potlodenshop",
-			Name:                 "website",
-			Target:               "CMD",
-			Status:               "running",
-			Ports:                []uint{8080, 443},
-			NetworkName:          "dummy-network",
-			Environment: EnvironmentInformation{
-				Name:  "dev",
-				Stage: "development",
-			},
-		}, ContainerInformation{
-			ID:                   "dev-sharpening-id",
-			Locator:              "flip-agency/potlodenshop/sharpening-service?env=dev",
-			SourceOrganization:   "flip-agency",
-			SourceRepository:     "potlodenshop",
-			UmbrellaOrganization: "flip-agency",
-			UmbrellaRepository:   "potlodenshop",
-			Name:                 "sharpening-service",
-			Target:               "CMD",
-			Status:               "stopped",
-			Ports:                []uint{80},
-			NetworkName:          "dummy-network",
-			Environment: EnvironmentInformation{
-				Name:  "dev",
-				Stage: "development",
-			},
-		},
-		// PROD
-		ContainerInformation{
-			ID:                   "prod-website-id",
-			Locator:              "flip-agency/potlodenshop/website?env=prod",
-			SourceOrganization:   "flip-agency",
-			SourceRepository:     "potlodenshop",
-			UmbrellaOrganization: "flip-agency",
-			UmbrellaRepository:   "potlodenshop",
-			Name:                 "website",
-			Target:               "CMD",
-			Status:               "running",
-			Ports:                []uint{8080, 443},
-			NetworkName:          "dummy-network",
-			Environment: EnvironmentInformation{
-				Name:  "prod",
-				Stage: "production",
-			},
-		},
-		ContainerInformation{
-			ID:                   "prod-sharpening-id",
-			Locator:              "flip-agency/potlodenshop/sharpening-service?env=prod",
-			SourceOrganization:   "flip-agency",
-			SourceRepository:     "potlodenshop",
-			UmbrellaOrganization: "flip-agency",
-			UmbrellaRepository:   "potlodenshop",
-			Name:                 "sharpening-service",
-			Target:               "CMD",
-			Status:               "running",
-			Ports:                []uint{80},
-			NetworkName:          "dummy-network",
-			Environment: EnvironmentInformation{
-				Name:  "prod",
-				Stage: "production",
-			},
-		},
-	)
-
-	return result.Data, nil
-}
-
-func GetOrchestratorContainerListUrl(isLocal bool) string {
-	if isLocal {
-		return OrchestratorApiLocalhost + "/container_list"
+	if len(missing) == 0 {
+		return ""
 	}
-	return OrchestratorApiDefault + "/container_list"
+	return "no " + strings.Join(missing, ", ")
 }
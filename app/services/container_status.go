@@ -0,0 +1,67 @@
+package services
+
+import (
+	"src/app/services/orchestrator"
+	"time"
+)
+
+// TrackedContainer is a container with the moment it got its current status.
+type TrackedContainer struct {
+	orchestrator.ContainerInformation
+	Since time.Time
+	// PreviousStatus is the status before the last transition, empty when unknown
+	PreviousStatus string
+	// ChangedAt is the moment the transition was seen, zero when it has not changed while tracking
+	ChangedAt time.Time
+}
+
+// ContainerStatusTracker remembers the status of containers between refreshes,
+// e.g. to show how long a container is running.
+type ContainerStatusTracker struct {
+	containers map[string]TrackedContainer
+}
+
+func NewContainerStatusTracker() *ContainerStatusTracker {
+	return &ContainerStatusTracker{containers: map[string]TrackedContainer{}}
+}
+
+// Update stores the current state of the containers and returns them with their history.
+func (t *ContainerStatusTracker) Update(containers []orchestrator.ContainerInformation, now time.Time) []TrackedContainer {
+	result := []TrackedContainer{}
+	seen := map[string]bool{}
+	for _, container := range containers {
+		seen[container.ID] = true
+		tracked, ok := t.containers[container.ID]
+		switch {
+		case !ok:
+			tracked = TrackedContainer{Since: statusSince(container, now)}
+		case tracked.Status != container.Status:
+			tracked.PreviousStatus = tracked.Status
+			tracked.Since = statusSince(container, now)
+			tracked.ChangedAt = now
+		}
+		tracked.ContainerInformation = container
+		t.containers[container.ID] = tracked
+		result = append(result, tracked)
+	}
+	// Forget the containers that are removed
+	for id := range t.containers {
+		if !seen[id] {
+			delete(t.containers, id)
+		}
+	}
+	return result
+}
+
+// statusSince returns when the container got its status. Only the start of a
+// running container is known, otherwise it is the moment we see the status.
+func statusSince(container orchestrator.ContainerInformation, now time.Time) time.Time {
+	if container.Status != "running" || container.StartedAt == "" {
+		return now
+	}
+	started, err := time.Parse(time.RFC3339, container.StartedAt)
+	if err != nil || started.After(now) {
+		return now
+	}
+	return started
+}
@@ -2,6 +2,10 @@ package services
 
 import (
 	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,53 +24,251 @@ func DownloadZip(cli inter.Cli, url string, outputPath string, env Environment)
 		return err
 	}
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+	file, err := DownloadFile(cli.Writer(), url, token)
+	if err != nil {
+		return err
 	}
 
-	if config.App.VeryVeryVerbose {
-		fmt.Printf("Method: %s, URL: %s\n", "GET", url)
+	// Unzip the file to the outputPath
+	err = Unzip(file, outputPath)
+	if err != nil {
+		return fmt.Errorf("error unzipping file %s: %w", file, err)
+	}
+	// Only remove the file when it is used, otherwise the next run can't resume it
+	removePartialDownload(file)
+
+	fmt.Printf("File downloaded and unzipped successfully to %s\n", outputPath)
+	return nil
+}
+
+// DownloadFile downloads the url to a temporary file and returns the path of
+// that file. An interrupted download is resumed with an HTTP Range request,
+// also when the command is started again. The ETag or Last-Modified of the
+// partial file is sent with If-Range, so a file that changed on the server
+// is downloaded again. When the server sends a SHA-256 digest, the file is
+// verified before it is returned.
+func DownloadFile(writer io.Writer, url string, token string) (string, error) {
+	partPath := partialDownloadPath(url)
+	digest := ""
+	var err error
+	for attempt := 1; attempt <= config.Download.MaxAttempts; attempt++ {
+		var retry bool
+		digest, retry, err = downloadPart(writer, url, token, partPath, digest)
+		if err == nil || !retry {
+			break
+		}
+		if attempt < config.Download.MaxAttempts {
+			fmt.Printf("\nDownload interrupted (%s), resuming...\n", err)
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("error downloading %s, run the command again to resume the download: %w", url, err)
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	if digest == "" {
+		if config.App.VeryVerbose {
+			fmt.Printf("No SHA-256 digest received for %s, skipping verification\n", url)
+		}
+		return partPath, nil
+	}
+	actual, err := sha256OfFile(partPath)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if actual != digest {
+		// The file is corrupt, so resuming it next time makes no sense
+		removePartialDownload(partPath)
+		return "", fmt.Errorf("checksum mismatch for %s: expected SHA-256 %s, got %s", url, digest, actual)
+	}
+	if config.App.VeryVerbose {
+		fmt.Printf("SHA-256 of %s verified: %s\n", url, digest)
+	}
+	return partPath, nil
+}
+
+// downloadPart continues the download in partPath. It returns the digest
+// given by the server (or the digest it already knew) and whether
+// it makes sense to try again after an error.
+func downloadPart(writer io.Writer, url, token, partPath, digest string) (string, bool, error) {
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+	validator, _ := os.ReadFile(partPath + ".validator")
+	if offset > 0 && len(validator) == 0 {
+		// Without a validator we can't know if the partial file is of the same file
+		removePartialDownload(partPath)
+		offset = 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return digest, false, err
 	}
 	req.Header.Add("Authorization", "Bearer "+token)
+	if offset > 0 {
+		req.Header.Add("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Add("If-Range", string(validator))
+	}
 
-	// Perform the request
-	res, err := client.Do(req)
+	if config.App.VeryVeryVerbose {
+		fmt.Printf("Method: %s, URL: %s, Offset: %d\n", "GET", url, offset)
+	}
+
+	// Perform the request, the timeout for the body is set when we know the size
+	res, err := downloadClient.Do(req)
 	if err != nil {
-		return err
+		return digest, true, err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode > 299 {
-		return fmt.Errorf("error with status: %d while downloading from: %s", res.StatusCode, url)
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	switch {
+	case res.StatusCode == http.StatusPartialContent:
+		if config.App.Verbose {
+			fmt.Printf("Resume download at %d bytes\n", offset)
+		}
+	case res.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file doesn't match the file on the server anymore
+		removePartialDownload(partPath)
+		return "", true, fmt.Errorf("partial download of %d bytes is invalid", offset)
+	case res.StatusCode > 299:
+		retry := res.StatusCode >= http.StatusInternalServerError
+		return digest, retry, fmt.Errorf("error with status: %d while downloading from: %s", res.StatusCode, url)
+	default:
+		// The server doesn't support ranges or we start from scratch
+		offset = 0
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
 	}
 
-	// Create a temporary file to store the downloaded zip
-	tempFile, err := os.CreateTemp("", "download-*.zip")
-	if err != nil {
-		return fmt.Errorf("error creating temp file: %w", err)
+	if d := sha256DigestFromHeader(res.Header, res.StatusCode); d != "" {
+		if digest != "" && d != digest {
+			// The file has changed on the server, start over
+			removePartialDownload(partPath)
+			return d, true, fmt.Errorf("the file on the server has changed during the download")
+		}
+		digest = d
 	}
-	defer os.Remove(tempFile.Name()) // Ensure the temp file is removed after use
-	defer tempFile.Close()
 
-	// Write the response body to the temp file
-	_, err = io.Copy(tempFile, res.Body)
+	total := int64(-1)
+	if res.ContentLength >= 0 {
+		total = offset + res.ContentLength
+	}
+	timeout := downloadTimeout(res.ContentLength)
+	timer := time.AfterFunc(timeout, cancel)
+	defer timer.Stop()
+
+	file, err := os.OpenFile(partPath, flag, 0644)
 	if err != nil {
-		return fmt.Errorf("error writing to temp file: %w", err)
+		return digest, false, fmt.Errorf("error creating temp file: %w", err)
+	}
+	defer file.Close()
+	if offset == 0 {
+		writeDownloadValidator(partPath, res.Header)
 	}
 
-	// Unzip the file to the outputPath
-	err = Unzip(tempFile.Name(), outputPath)
+	bar := getBytesBar(total, "Downloading", writer)
+	_ = bar.Set64(offset)
+	_, err = io.Copy(io.MultiWriter(file, bar), res.Body)
+	_ = bar.Finish()
+	fmt.Fprintln(writer)
+	if ctx.Err() != nil {
+		return digest, true, fmt.Errorf("download timed out after %s", timeout)
+	}
 	if err != nil {
-		return fmt.Errorf("error unzipping file %s: %w", tempFile.Name(), err)
+		return digest, true, fmt.Errorf("error writing to temp file: %w", err)
 	}
+	return digest, false, nil
+}
 
-	fmt.Printf("File downloaded and unzipped successfully to %s\n", outputPath)
-	return nil
+var downloadClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: 30 * time.Second,
+	},
+}
+
+// downloadTimeout gives large downloads more time. The size is -1 when unknown.
+func downloadTimeout(size int64) time.Duration {
+	if config.Download.Timeout > 0 {
+		return config.Download.Timeout
+	}
+	if size < 0 || config.Download.MinBytesPerSecond <= 0 {
+		return 10 * time.Minute
+	}
+	return 30*time.Second + time.Duration(size/config.Download.MinBytesPerSecond)*time.Second
+}
+
+// partialDownloadPath is the same for the same url, so a new run can resume it.
+func partialDownloadPath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(os.TempDir(), "confetti-download-"+hex.EncodeToString(sum[:8])+".part")
+}
+
+// writeDownloadValidator stores the ETag (or Last-Modified) next to the
+// partial file, so a next request only resumes the same version of the
+// file. A weak ETag can't be used for a Range request.
+func writeDownloadValidator(partPath string, header http.Header) {
+	validator := header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = header.Get("Last-Modified")
+	}
+	if validator == "" {
+		_ = os.Remove(partPath + ".validator")
+		return
+	}
+	_ = os.WriteFile(partPath+".validator", []byte(validator), 0644)
+}
+
+func removePartialDownload(partPath string) {
+	_ = os.Remove(partPath)
+	_ = os.Remove(partPath + ".validator")
+}
+
+// sha256DigestFromHeader returns the hex encoded SHA-256 digest of the whole
+// file. We support Repr-Digest (RFC 9530), Digest (RFC 3230) and
+// X-Checksum-Sha256. Content-Digest is only about this response,
+// so only usable when we received the whole file.
+func sha256DigestFromHeader(header http.Header, status int) string {
+	for _, name := range []string{"Repr-Digest", "Content-Digest", "Digest"} {
+		if name == "Content-Digest" && status != http.StatusOK {
+			continue
+		}
+		for _, value := range header.Values(name) {
+			for _, part := range strings.Split(value, ",") {
+				algorithm, encoded, found := strings.Cut(strings.TrimSpace(part), "=")
+				if !found || !strings.EqualFold(algorithm, "sha-256") {
+					continue
+				}
+				raw, err := base64.StdEncoding.DecodeString(strings.Trim(encoded, ":"))
+				if err != nil || len(raw) != sha256.Size {
+					continue
+				}
+				return hex.EncodeToString(raw)
+			}
+		}
+	}
+	if value := strings.TrimSpace(header.Get("X-Checksum-Sha256")); value != "" {
+		return strings.ToLower(value)
+	}
+	return ""
+}
+
+func sha256OfFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate checksum of %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 // Unzip extracts a zip file to the specified destination directory
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"src/app/services/orchestrator"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+// NewOrchestratorClient returns a client for the orchestrator of the environment
+// (see Environment.GetOrchestratorApi), logged in as the current user.
+func NewOrchestratorClient(cli inter.Cli, env Environment) *orchestrator.Client {
+	return orchestrator.NewClient(env.GetOrchestratorApi(), func() (string, error) {
+		return GetAccessToken(cli, env)
+	})
+}
@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"src/config"
+	"strings"
+	"time"
+)
+
+// Client talks to the orchestrator API that runs the containers of the
+// environments. Every request is authenticated with the token of Token.
+type Client struct {
+	BaseUrl    string
+	HttpClient *http.Client
+	// StreamClient is used for long running requests, by default without a timeout
+	StreamClient *http.Client
+	Token        func() (string, error)
+}
+
+func NewClient(baseUrl string, token func() (string, error)) *Client {
+	return &Client{
+		BaseUrl:    strings.TrimRight(baseUrl, "/"),
+		HttpClient: &http.Client{Timeout: 30 * time.Second},
+		Token:      token,
+	}
+}
+
+func (c *Client) Url(endpoint string) string {
+	return strings.TrimRight(c.BaseUrl, "/") + "/" + strings.TrimLeft(endpoint, "/")
+}
+
+// do sends the request and decodes the `data` of the response into result.
+func (c *Client) do(method string, endpoint string, query url.Values, body any, result any) error {
+	requestUrl := c.Url(endpoint)
+	if len(query) > 0 {
+		requestUrl += "?" + query.Encode()
+	}
+
+	var payload io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("unable to encode orchestrator request: %w", err)
+		}
+		payload = bytes.NewReader(content)
+	}
+	req, err := http.NewRequest(method, requestUrl, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "application/json")
+	if c.Token != nil {
+		token, err := c.Token()
+		if err != nil {
+			return err
+		}
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+
+	if config.App.VeryVeryVerbose {
+		fmt.Printf("Orchestrator request: %s %s\n", method, requestUrl)
+	}
+	res, err := c.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("orchestrator request %s %s failed: %w", method, requestUrl, err)
+	}
+	defer res.Body.Close()
+	responseBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("error reading orchestrator response: %w", err)
+	}
+	if res.StatusCode > 299 {
+		return newError(res.StatusCode, method, requestUrl, responseBody)
+	}
+	if result == nil || len(bytes.TrimSpace(responseBody)) == 0 {
+		return nil
+	}
+
+	response := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	err = json.Unmarshal(responseBody, &response)
+	if err != nil {
+		return fmt.Errorf("invalid orchestrator response of %s %s: %w", method, requestUrl, err)
+	}
+	if len(response.Data) == 0 {
+		return nil
+	}
+	err = json.Unmarshal(response.Data, result)
+	if err != nil {
+		return fmt.Errorf("invalid orchestrator response of %s %s: %w", method, requestUrl, err)
+	}
+	return nil
+}
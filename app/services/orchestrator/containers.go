@@ -0,0 +1,144 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func (o QueryContainerOptions) values() url.Values {
+	query := url.Values{}
+	if o.Environment != "" {
+		query.Set("environment", o.Environment)
+	}
+	if o.UmbrellaOrganization != "" {
+		query.Set("umbrella_organization", o.UmbrellaOrganization)
+	}
+	if o.UmbrellaRepository != "" {
+		query.Set("umbrella_repository", o.UmbrellaRepository)
+	}
+	return query
+}
+
+func (c *Client) ListContainers(options QueryContainerOptions) ([]ContainerInformation, error) {
+	containers := []ContainerInformation{}
+	err := c.do(http.MethodGet, "/container_list", options.values(), nil, &containers)
+	if err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+func (c *Client) GetContainer(id string) (ContainerInformation, error) {
+	container := ContainerInformation{}
+	err := c.do(http.MethodGet, "/container_get", url.Values{"id": {id}}, nil, &container)
+	return container, err
+}
+
+// GetEnvironmentStatus returns the status of an environment with all its containers.
+func (c *Client) GetEnvironmentStatus(environment string) (EnvironmentStatus, error) {
+	status := EnvironmentStatus{}
+	err := c.do(http.MethodGet, "/environment_status", url.Values{"environment": {environment}}, nil, &status)
+	return status, err
+}
+
+type ContainerRequest struct {
+	ContainerID string `json:"id"`
+}
+
+func (c *Client) StartContainer(id string) error {
+	return c.do(http.MethodPost, "/container_start", nil, ContainerRequest{id}, nil)
+}
+
+func (c *Client) StopContainer(id string) error {
+	return c.do(http.MethodPost, "/container_stop", nil, ContainerRequest{id}, nil)
+}
+
+func (c *Client) RestartContainer(id string) error {
+	return c.do(http.MethodPost, "/container_restart", nil, ContainerRequest{id}, nil)
+}
+
+// WaitForStatus polls the container until it has one of the statuses. Every
+// status the container passes is reported to onChange.
+func (c *Client) WaitForStatus(id string, statuses []string, timeout time.Duration, interval time.Duration, onChange func(status string)) (ContainerInformation, error) {
+	deadline := time.Now().Add(timeout)
+	previous := ""
+	for {
+		container, err := c.GetContainer(id)
+		if err != nil {
+			return container, err
+		}
+		if container.Status != previous {
+			previous = container.Status
+			if onChange != nil {
+				onChange(container.Status)
+			}
+		}
+		for _, status := range statuses {
+			if container.Status == status {
+				return container, nil
+			}
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return container, fmt.Errorf("container %s is still %s after %s, expected %s", id, container.Status, timeout, strings.Join(statuses, " or "))
+		}
+		time.Sleep(interval)
+	}
+}
+
+// WaitForRestart polls the container until it is restarted and running
+// again. Before the restart the container is usually running already, so it
+// only counts as restarted when it has left the running status or has a new
+// start time.
+func (c *Client) WaitForRestart(before ContainerInformation, timeout time.Duration, interval time.Duration, onChange func(status string)) (ContainerInformation, error) {
+	deadline := time.Now().Add(timeout)
+	previous := before.Status
+	restarted := false
+	for {
+		container, err := c.GetContainer(before.ID)
+		if err != nil {
+			return container, err
+		}
+		if container.Status != previous {
+			previous = container.Status
+			if onChange != nil {
+				onChange(container.Status)
+			}
+		}
+		if container.Status != "running" || (container.StartedAt != "" && container.StartedAt != before.StartedAt) {
+			restarted = true
+		}
+		if restarted && container.Status == "running" {
+			return container, nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			if !restarted {
+				return container, fmt.Errorf("container %s is not restarted after %s", before.ID, timeout)
+			}
+			return container, fmt.Errorf("container %s is still %s after %s, expected running", before.ID, container.Status, timeout)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// ContainerEvent is pushed by the orchestrator when a container changes.
+type ContainerEvent struct {
+	ContainerID string `json:"container_id"`
+	Status      string `json:"status"`
+}
+
+// StreamContainerEvents calls handle for every change of the containers that
+// match the query, until the orchestrator closes the stream.
+func (c *Client) StreamContainerEvents(options QueryContainerOptions, handle func(ContainerEvent) error) error {
+	return c.stream(http.MethodGet, "/container_events", options.values(), nil, func(raw []byte) error {
+		event := ContainerEvent{}
+		err := json.Unmarshal(raw, &event)
+		if err != nil {
+			return fmt.Errorf("invalid container event from orchestrator: %w", err)
+		}
+		return handle(event)
+	})
+}
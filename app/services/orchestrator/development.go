@@ -0,0 +1,16 @@
+package orchestrator
+
+import "net/http"
+
+// StartDevelopment starts the development containers of a repository in an environment.
+func (c *Client) StartDevelopment(environment string, repository string) error {
+	return c.do(http.MethodPost, "/start_development", nil, DevelopmentRequest{environment, repository}, nil)
+}
+
+func (c *Client) StopDevelopment(environment string, repository string) error {
+	return c.do(http.MethodPost, "/stop_development", nil, DevelopmentRequest{environment, repository}, nil)
+}
+
+func (c *Client) RestartDevelopment(environment string, repository string) error {
+	return c.do(http.MethodPost, "/restart_development", nil, DevelopmentRequest{environment, repository}, nil)
+}
@@ -0,0 +1,62 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error is returned when the orchestrator responds with an unsuccessful status.
+type Error struct {
+	StatusCode int
+	Method     string
+	Url        string
+	// Titles are the titles of the errors in the response, e.g. {"errors": [{"title": "..."}]}
+	Titles []string
+	Body   string
+}
+
+func (e *Error) Error() string {
+	if len(e.Titles) > 0 {
+		return fmt.Sprintf("orchestrator responded with status %d: %s", e.StatusCode, strings.Join(e.Titles, ", "))
+	}
+	return fmt.Sprintf("orchestrator responded with status %d on %s %s: %s", e.StatusCode, e.Method, e.Url, e.Body)
+}
+
+func newError(statusCode int, method string, url string, body []byte) *Error {
+	err := &Error{StatusCode: statusCode, Method: method, Url: url, Body: strings.TrimSpace(string(body))}
+	response := struct {
+		Errors []struct {
+			Title string `json:"title"`
+		} `json:"errors"`
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(body, &response) == nil {
+		for _, e := range response.Errors {
+			if e.Title != "" {
+				err.Titles = append(err.Titles, e.Title)
+			}
+		}
+		if len(err.Titles) == 0 && response.Message != "" {
+			err.Titles = append(err.Titles, response.Message)
+		}
+	}
+	return err
+}
+
+// IsNotFound reports whether the orchestrator could not find the requested resource.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether the user is not (or no longer) logged in or allowed.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized) || hasStatus(err, http.StatusForbidden)
+}
+
+func hasStatus(err error, status int) bool {
+	var orchestratorErr *Error
+	return errors.As(err, &orchestratorErr) && orchestratorErr.StatusCode == status
+}
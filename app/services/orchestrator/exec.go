@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type ExecRequest struct {
+	ContainerID string   `json:"id"`
+	Command     []string `json:"command"`
+}
+
+// ExecFrame is a part of the output of a command. The last frame contains the exit code.
+type ExecFrame struct {
+	Stream   string `json:"stream"` // stdout or stderr
+	Data     string `json:"data"`
+	ExitCode *int   `json:"exit_code"`
+}
+
+// Exec runs a command in the container and streams the output to stdout and
+// stderr. It returns the exit code of the command.
+func (c *Client) Exec(containerID string, command []string, stdout io.Writer, stderr io.Writer) (int, error) {
+	exitCode := -1
+	err := c.stream(http.MethodPost, "/container_exec", nil, ExecRequest{containerID, command}, func(raw []byte) error {
+		frame := ExecFrame{}
+		err := json.Unmarshal(raw, &frame)
+		if err != nil {
+			return fmt.Errorf("invalid exec output from orchestrator: %w", err)
+		}
+		if frame.Stream == "stderr" {
+			_, err = io.WriteString(stderr, frame.Data)
+		} else {
+			_, err = io.WriteString(stdout, frame.Data)
+		}
+		if frame.ExitCode != nil {
+			exitCode = *frame.ExitCode
+		}
+		return err
+	})
+	if err != nil {
+		return exitCode, err
+	}
+	if exitCode == -1 {
+		return exitCode, fmt.Errorf("the orchestrator closed the connection before the command finished")
+	}
+	return exitCode, nil
+}
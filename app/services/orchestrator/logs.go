@@ -0,0 +1,126 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type LogOptions struct {
+	Follow bool
+	// Since only returns the lines after this moment, ignored when zero
+	Since time.Time
+	// Tail is the number of lines before following, all lines when 0
+	Tail int
+	// OnReconnect is called before FollowLogs reconnects, err is nil when the stream was closed
+	OnReconnect func(err error, delay time.Duration)
+}
+
+type LogLine struct {
+	ContainerID string    `json:"container_id"`
+	Stream      string    `json:"stream"` // stdout or stderr
+	Time        time.Time `json:"time"`
+	Message     string    `json:"message"`
+}
+
+// StreamLogs calls handle for every log line of the container. With Follow,
+// it only returns when the orchestrator closes the stream or handle fails.
+func (c *Client) StreamLogs(containerID string, options LogOptions, handle func(LogLine) error) error {
+	query := url.Values{"id": {containerID}}
+	if options.Follow {
+		query.Set("follow", "1")
+	}
+	if !options.Since.IsZero() {
+		query.Set("since", options.Since.UTC().Format(time.RFC3339Nano))
+	}
+	if options.Tail > 0 {
+		query.Set("tail", strconv.Itoa(options.Tail))
+	}
+	return c.stream(http.MethodGet, "/container_logs", query, nil, func(raw []byte) error {
+		line := LogLine{}
+		err := json.Unmarshal(raw, &line)
+		if err != nil {
+			return fmt.Errorf("invalid log line from orchestrator: %w", err)
+		}
+		if line.ContainerID == "" {
+			line.ContainerID = containerID
+		}
+		err = handle(line)
+		if err != nil {
+			return handleError{err}
+		}
+		return nil
+	})
+}
+
+// handleError is an error of the handler, these errors stop following the logs.
+type handleError struct {
+	error
+}
+
+func (e handleError) Unwrap() error {
+	return e.error
+}
+
+// maxReconnects is the number of reconnects in a row without receiving a line
+const maxReconnects = 10
+
+// FollowLogs streams the logs like StreamLogs, but reconnects when the stream
+// is interrupted. After a reconnect, the logs continue after the last line.
+func (c *Client) FollowLogs(containerID string, options LogOptions, handle func(LogLine) error) error {
+	options.Follow = true
+	delay := time.Second
+	attempts := 0
+	// The lines with the time of the last line. Other lines can have the same
+	// time, so we continue at that time and skip the lines we already handled.
+	handled := map[string]int{}
+	for {
+		received := false
+		since := options.Since
+		skip := maps.Clone(handled)
+		err := c.StreamLogs(containerID, options, func(line LogLine) error {
+			key := line.Stream + "\n" + line.Message
+			if !line.Time.IsZero() && line.Time.Equal(since) && skip[key] > 0 {
+				skip[key]--
+				return nil
+			}
+			received = true
+			if !line.Time.IsZero() {
+				if !line.Time.Equal(options.Since) {
+					options.Since = line.Time
+					handled = map[string]int{}
+				}
+				handled[key]++
+			}
+			// The lines before the reconnect are already handled
+			options.Tail = 0
+			return handle(line)
+		})
+		if IsNotFound(err) || IsUnauthorized(err) {
+			return err
+		}
+		if _, ok := err.(handleError); ok {
+			return err
+		}
+		if received {
+			delay = time.Second
+			attempts = 0
+		}
+		attempts++
+		if attempts > maxReconnects {
+			if err == nil {
+				err = fmt.Errorf("the orchestrator closed the stream")
+			}
+			return fmt.Errorf("unable to follow the logs of %s after %d attempts: %w", containerID, maxReconnects, err)
+		}
+		if options.OnReconnect != nil {
+			options.OnReconnect(err, delay)
+		}
+		time.Sleep(delay)
+		delay = min(delay*2, 30*time.Second)
+	}
+}
@@ -0,0 +1,40 @@
+package orchestrator
+
+type ContainerInformation struct {
+	ID                   string                 `json:"id" yaml:"id"`
+	Locator              string                 `json:"locator" yaml:"locator"`
+	SourceOrganization   string                 `json:"source_organization" yaml:"source_organization"`
+	SourceRepository     string                 `json:"source_repository" yaml:"source_repository"`
+	UmbrellaOrganization string                 `json:"umbrella_organization" yaml:"umbrella_organization"`
+	UmbrellaRepository   string                 `json:"umbrella_repository" yaml:"umbrella_repository"`
+	Name                 string                 `json:"name" yaml:"name"`
+	Target               string                 `json:"target" yaml:"target"`
+	Status               string                 `json:"status" yaml:"status"`
+	Ports                []uint                 `json:"ports" yaml:"ports"`
+	NetworkName          string                 `json:"network_name" yaml:"network_name"`
+	StartedAt            string                 `json:"started_at,omitempty" yaml:"started_at,omitempty"`
+	Environment          EnvironmentInformation `json:"environment" yaml:"environment"`
+}
+
+type EnvironmentInformation struct {
+	Name  string `json:"name" yaml:"name"`
+	Stage string `json:"stage" yaml:"stage"`
+}
+
+type QueryContainerOptions struct {
+	Environment          string `json:"environment" yaml:"environment"`
+	UmbrellaOrganization string `json:"umbrella_organization" yaml:"umbrella_organization"`
+	UmbrellaRepository   string `json:"umbrella_repository" yaml:"umbrella_repository"`
+}
+
+type DevelopmentRequest struct {
+	EnvironmentName string `json:"environment_name" yaml:"environment_name"`
+	Repository      string `json:"repository" yaml:"repository"`
+}
+
+type EnvironmentStatus struct {
+	Name       string                 `json:"name" yaml:"name"`
+	Stage      string                 `json:"stage" yaml:"stage"`
+	Status     string                 `json:"status" yaml:"status"`
+	Containers []ContainerInformation `json:"containers" yaml:"containers"`
+}
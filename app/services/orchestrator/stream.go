@@ -0,0 +1,88 @@
+package orchestrator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"src/config"
+	"time"
+)
+
+// streamClient has no overall timeout, because a stream (e.g. logs with
+// follow) can stay open as long as the user wants.
+var streamClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 30 * time.Second}).DialContext,
+		ResponseHeaderTimeout: 30 * time.Second,
+	},
+}
+
+// stream sends the request and calls handle for every line of the response
+// (newline delimited JSON) until the orchestrator closes the stream.
+func (c *Client) stream(method string, endpoint string, query url.Values, body any, handle func(line []byte) error) error {
+	requestUrl := c.Url(endpoint)
+	if len(query) > 0 {
+		requestUrl += "?" + query.Encode()
+	}
+	var payload io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("unable to encode orchestrator request: %w", err)
+		}
+		payload = bytes.NewReader(content)
+	}
+	req, err := http.NewRequest(method, requestUrl, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/x-ndjson")
+	req.Header.Add("Content-Type", "application/json")
+	if c.Token != nil {
+		token, err := c.Token()
+		if err != nil {
+			return err
+		}
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+
+	if config.App.VeryVeryVerbose {
+		fmt.Printf("Orchestrator stream: %s %s\n", method, requestUrl)
+	}
+	client := streamClient
+	if c.StreamClient != nil {
+		client = c.StreamClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("orchestrator stream %s %s failed: %w", method, requestUrl, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		responseBody, _ := io.ReadAll(res.Body)
+		return newError(res.StatusCode, method, requestUrl, responseBody)
+	}
+
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		err = handle(line)
+		if err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("orchestrator stream %s %s interrupted: %w", method, requestUrl, err)
+	}
+	return nil
+}
@@ -64,65 +64,80 @@ func RestoreDirectory(pkg string) (bool, error) {
 	return true, nil
 }
 
-func AddNewPackage(pkg string) error {
-	cmd := fmt.Sprintf("cd %s && git subtree add --prefix=\"pkg/%s\" git@github.com:%s.git main", config.Path.Root, pkg, pkg)
-	output, err := RunCommand(cmd)
+func AddNewPackage(pkg string, remote PackageRemote, ref string) error {
+	commit, err := fetchSubtree(remote, ref)
 	if err != nil {
 		// Check if the error is due to branch not existing
-		if strings.Contains(output, "doesn't exist") || strings.Contains(output, "not found") {
-			fmt.Printf("\n\033[31mThe branch 'main' does not exist in repository '%s'.\n\033[0m", pkg)
-			fmt.Println("Please create the repository and initial commit first. You can use the following commands to initialize it:")
-			fmt.Println()
-			fmt.Printf("\033[32mcd /path/to/%s\n\033[0m", pkg)
-			fmt.Printf("\033[32mecho \"# %s\" >> README.md\n\033[0m", pkg)
-			fmt.Printf("\033[32mgit init\n\033[0m")
-			fmt.Printf("\033[32mgit add README.md\n\033[0m")
-			fmt.Printf("\033[32mgit commit -m \"first commit\"\n\033[0m")
-			fmt.Printf("\033[32mgit branch -M main\n\033[0m")
-			fmt.Printf("\033[32mgit remote add origin git@github.com:%s.git\n\033[0m", pkg)
-			fmt.Printf("\033[32mgit push -u origin main\n\033[0m")
-			fmt.Printf("\033[32mcd -\n\033[0m")
-			fmt.Println()
-			return fmt.Errorf("branch 'main' does not exist in repository %s, please create it first", pkg)
+		if strings.Contains(err.Error(), "couldn't find remote ref") {
+			fmt.Printf("\n\033[31mThe branch '%s' does not exist in repository '%s'.\n\033[0m", ref, pkg)
+			PrintCreatePackageHint(pkg)
+			return fmt.Errorf("branch '%s' does not exist in repository %s, please create it first", ref, pkg)
 		}
 		return fmt.Errorf("error adding new package %s: %w", pkg, err)
 	}
+	err = subtreeAdd("pkg/"+pkg, commit)
+	if err != nil {
+		return fmt.Errorf("error adding new package %s: %w", pkg, err)
+	}
 	return nil
 }
 
-func PullLatestChanges(pkg string) error {
-	cmd := fmt.Sprintf("cd %s && git subtree pull --message=\"Pull package %s\" --prefix=\"pkg/%s\" git@github.com:%s.git main", config.Path.Root, pkg, pkg, pkg)
-	// We can't use StreamCommand here (for now) because the command gives exit code 1 (if there are no changes).
-	output, err := RunCommand(cmd)
+func PullLatestChanges(pkg string, remote PackageRemote, ref string) error {
+	commit, err := fetchSubtree(remote, ref)
 	if err != nil {
 		// Check if the error is due to repository not existing
-		if strings.Contains(output, "Repository not found") || strings.Contains(output, "does not exist") {
+		if strings.Contains(err.Error(), "Repository not found") || strings.Contains(err.Error(), "does not exist") || strings.Contains(err.Error(), "couldn't find remote ref") {
 			fmt.Printf("\n\033[31mThe repository '%s' does not exist.\n\033[0m", pkg)
-			fmt.Println("Please create the repository first. You can use the following commands to initialize it:")
-			fmt.Println()
-			fmt.Printf("\033[32mcd /path/to/%s\n\033[0m", pkg)
-			fmt.Printf("\033[32mecho \"# %s\" >> README.md\n\033[0m", pkg)
-			fmt.Printf("\033[32mgit init\n\033[0m")
-			fmt.Printf("\033[32mgit add README.md\n\033[0m")
-			fmt.Printf("\033[32mgit commit -m \"first commit\"\n\033[0m")
-			fmt.Printf("\033[32mgit branch -M main\n\033[0m")
-			fmt.Printf("\033[32mgit remote add origin git@github.com:%s.git\n\033[0m", pkg)
-			fmt.Printf("\033[32mgit push -u origin main\n\033[0m")
-			fmt.Printf("\033[32mcd -\n\033[0m")
-			fmt.Println()
+			PrintCreatePackageHint(pkg)
 			return fmt.Errorf("repository %s does not exist, please create it first", pkg)
 		}
+		return fmt.Errorf("error fetching package %s: %w", pkg, err)
+	}
+	// A merge can't go back, so an older version would leave pkg/ at the newer version
+	_, pulled, err := LastSubtreeMerge(pkg)
+	if err != nil {
+		return err
+	}
+	if pulled != "" && pulled != commit {
+		_, err = runGit(nil, "", "merge-base", "--is-ancestor", commit, pulled)
+		if err == nil {
+			return fmt.Errorf("%s is older than the version of package %s in pkg/, remove the package first (conf pkg:remove -p %s) to pull an older version", ref, pkg, pkg)
+		}
+	}
+	merged, err := subtreeMerge("pkg/"+pkg, commit, fmt.Sprintf("Pull package %s %s", pkg, ref))
+	if err != nil {
+		if MergeInProgress() {
+			files, conflictErr := PackageConflictedFiles(pkg)
+			if conflictErr != nil {
+				return conflictErr
+			}
+			if len(files) > 0 {
+				return &PackageConflictError{Package: pkg, Files: files}
+			}
+		}
 		return err
 	}
+	if !merged && config.App.Verbose {
+		fmt.Printf("Package %s is already up to date with %s\n", pkg, ref)
+	}
 	return nil
 }
 
-func PushPackage(pkg string) error {
-	cmd := fmt.Sprintf("cd %s && git subtree push --prefix=\"pkg/%s\" git@github.com:%s.git main", config.Path.Root, pkg, pkg)
-	_, err := StreamCommand(cmd)
+// PushPackage pushes the package to the branch, when the branch has no commits that are not pulled.
+func PushPackage(pkg string, remote PackageRemote, branch string) error {
+	plan, err := PlanPackagePush(pkg, remote, branch, false)
 	if err != nil {
 		return err
 	}
+	return ExecutePackagePush(remote, plan, false)
+}
+
+// StagePackage adds all files of the package (including new files) to the git index.
+func StagePackage(pkg string) error {
+	_, err := runGit(nil, "", "add", "-A", "--", "pkg/"+pkg)
+	if err != nil {
+		return fmt.Errorf("error adding package %s to git: %w", pkg, err)
+	}
 	return nil
 }
 
@@ -154,17 +169,42 @@ func PkgPackageContainsPhpFiles(pkg string) bool {
 	return found
 }
 
-func UpdateComposer(pkg string) error {
-	cmd := fmt.Sprintf("cd %s && composer require %s \"*\" --ignore-platform-reqs", config.Path.Root, pkg)
+// UpdateComposer requires the packages with one composer run.
+func UpdateComposer(pkgs ...string) error {
+	requires := ""
+	for _, pkg := range pkgs {
+		requires += fmt.Sprintf(" \"%s:*\"", pkg)
+	}
+	cmd := fmt.Sprintf("cd %s && composer require%s --ignore-platform-reqs", config.Path.Root, requires)
 	_, err := RunCommand(cmd)
 	if err != nil {
-		return fmt.Errorf("error updating composer for package %s: %w", pkg, err)
+		return fmt.Errorf("error updating composer for package %s: %w", strings.Join(pkgs, ", "), err)
+	}
+	return nil
+}
+
+// ComposerRemove removes the package from composer.json, composer.lock and the vendor directory.
+func ComposerRemove(pkg string) error {
+	cmd := fmt.Sprintf("cd %s && composer remove %s --ignore-platform-reqs --no-interaction", config.Path.Root, pkg)
+	_, err := RunCommand(cmd)
+	if err != nil {
+		return fmt.Errorf("error removing package %s from composer: %w", pkg, err)
+	}
+	return nil
+}
+
+// ComposerDumpAutoload generates the autoloader again, e.g. after the autoload entries are changed.
+func ComposerDumpAutoload() error {
+	cmd := fmt.Sprintf("cd %s && composer dump-autoload --no-interaction", config.Path.Root)
+	_, err := RunCommand(cmd)
+	if err != nil {
+		return fmt.Errorf("error generating the composer autoloader: %w", err)
 	}
 	return nil
 }
 
 func RemovePackage(pkg, msg string) error {
-	err := os.RemoveAll(filepath.Join("pkg", pkg))
+	err := os.RemoveAll(filepath.Join(config.Path.Root, "pkg", pkg))
 	if err != nil {
 		return fmt.Errorf("error removing package %s: %w", pkg, err)
 	}
@@ -204,6 +244,14 @@ func PrintPackageNoComposerMessage(pkg string) {
 
 }
 
+// PrintCreatePackageHint explains how to create a package that doesn't exist upstream yet.
+func PrintCreatePackageHint(pkg string) {
+	fmt.Println("Create the package and push it as the first version of the repository with:")
+	fmt.Println()
+	fmt.Printf("\033[32mconf pkg:create -p %s --push\n\033[0m", pkg)
+	fmt.Println()
+}
+
 func PrintPackageInstalledMessage(pkg string) {
 	fmt.Println("\n\033[32mThe package", pkg, "is now installed 🎁\n\033[0m")
 	fmt.Println("The package", pkg, "is fully integrated into your repository as if you wrote it yourself. You can make changes directly in your repository, and the commits will be saved to your own history. It’s perfectly fine to maintain a customized version of the package within your main project.")
@@ -215,11 +263,17 @@ func PrintPackagePulledMessage(pkg string) {
 }
 
 func namespaceExampleFromPackage(pkg string) string {
+	// Escaped, because the example is printed as JSON
+	return strings.ReplaceAll(packageNamespace(pkg), "\\", "\\\\")
+}
+
+// packageNamespace returns the PSR-4 namespace of a package, e.g. ConfettiCms\Text\ for confetti-cms/text.
+func packageNamespace(pkg string) string {
 	splitted := strings.Split(pkg, "/")
 	repo := toPascalCase(splitted[0])
 	name := toPascalCase(splitted[1])
 
-	return fmt.Sprintf("%s\\\\%s\\\\", repo, name)
+	return fmt.Sprintf("%s\\%s\\", repo, name)
 }
 
 func toPascalCase(s string) string {
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PackageConflictError is returned when the upstream changes of a package
+// conflict with the changes in the project. The merge is left in progress.
+type PackageConflictError struct {
+	Package string
+	// Files are relative to the package directory
+	Files []string
+}
+
+func (e *PackageConflictError) Error() string {
+	return fmt.Sprintf("pulling package %s gives conflicts in %s", e.Package, strings.Join(e.Files, ", "))
+}
+
+// MergeInProgress reports whether a merge (e.g. of a pull) is waiting to be concluded.
+func MergeInProgress() bool {
+	_, err := runGit(nil, "", "rev-parse", "-q", "--verify", "MERGE_HEAD")
+	return err == nil
+}
+
+// PackageConflictedFiles returns the conflicted files of the package, relative to the package directory.
+func PackageConflictedFiles(pkg string) ([]string, error) {
+	out, err := runGit(nil, "", "diff", "--name-only", "--diff-filter=U", "--", "pkg/"+pkg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the conflicts of package %s: %w", pkg, err)
+	}
+	files := []string{}
+	for _, file := range strings.FieldsFunc(out, func(r rune) bool { return r == '\n' }) {
+		files = append(files, strings.TrimPrefix(file, "pkg/"+pkg+"/"))
+	}
+	return files, nil
+}
+
+// AbortPackageMerge aborts the merge and restores the project to the commit before the pull.
+func AbortPackageMerge() error {
+	if !MergeInProgress() {
+		return fmt.Errorf("there is no pull to abort")
+	}
+	_, err := runGit(nil, "", "merge", "--abort")
+	if err != nil {
+		return fmt.Errorf("unable to abort the pull: %w", err)
+	}
+	return nil
+}
+
+// ResolvePackageConflict keeps the upstream version (theirs) or the version
+// of the project (ours) of a conflicted file. When that version is deleted,
+// the file is deleted.
+func ResolvePackageConflict(pkg string, file string, theirs bool) error {
+	side := "--ours"
+	if theirs {
+		side = "--theirs"
+	}
+	path := "pkg/" + pkg + "/" + file
+	_, err := runGit(nil, "", "checkout", side, "--", path)
+	if err != nil {
+		_, err = runGit(nil, "", "rm", "-q", "--", path)
+	}
+	if err == nil {
+		_, err = runGit(nil, "", "add", "-A", "--", path)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to resolve the conflict in %s: %w", path, err)
+	}
+	return nil
+}
+
+// ConcludePackageMerge commits the merge when all conflicts are resolved.
+func ConcludePackageMerge(pkg string) error {
+	out, err := runGit(nil, "", "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return fmt.Errorf("unable to check for conflicts: %w", err)
+	}
+	if strings.TrimSpace(out) != "" {
+		return fmt.Errorf("not all conflicts are resolved: %s", strings.Join(strings.Fields(out), ", "))
+	}
+	_, err = runGit(nil, "", "commit", "--no-edit")
+	if err != nil {
+		return fmt.Errorf("unable to commit the pull of package %s: %w", pkg, err)
+	}
+	return nil
+}
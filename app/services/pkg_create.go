@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"src/config"
+	"strings"
+)
+
+// packageNameRegex is the rule of composer for package names.
+var packageNameRegex = regexp.MustCompile(`^[a-z0-9]([_.-]?[a-z0-9]+)*/[a-z0-9](([_.]|-{1,2})?[a-z0-9]+)*$`)
+
+func ValidatePackageName(pkg string) error {
+	if !packageNameRegex.MatchString(pkg) {
+		return fmt.Errorf("%q is not a valid package name, use vendor/name in lowercase, e.g. confetti-cms/text", pkg)
+	}
+	return nil
+}
+
+// ScaffoldPackage creates pkg/<package> with a composer.json, a README and
+// a component. It returns the created files, relative to the project.
+func ScaffoldPackage(pkg string, description string) ([]string, error) {
+	dir := filepath.Join(config.Path.Root, "pkg", pkg)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("package directory pkg/%s already exists", pkg)
+	}
+	_, name, _ := strings.Cut(pkg, "/")
+	namespace := packageNamespace(pkg)
+	component := toPascalCase(name) + ComponentClassSuffix
+
+	composer := orderedJson{}
+	_ = composer.set("name", pkg)
+	if description != "" {
+		_ = composer.set("description", description)
+	}
+	_ = composer.set("type", "library")
+	psr4 := orderedJson{}
+	_ = psr4.set(namespace, "src/")
+	autoload := orderedJson{}
+	_ = autoload.set("psr-4", psr4)
+	_ = composer.set("autoload", autoload)
+	composerContent, err := marshalComposerJson(composer)
+	if err != nil {
+		return nil, err
+	}
+
+	readme := fmt.Sprintf("# %s\n\n", pkg)
+	if description != "" {
+		readme += description + "\n\n"
+	}
+	readme += fmt.Sprintf("Add this package to a Confetti project with:\n\n```\nconf pkg:pull -p %s\n```\n", pkg)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"composer.json", string(composerContent)},
+		{"README.md", readme},
+		{filepath.Join("src", component), fmt.Sprintf(`<?php
+
+declare(strict_types=1);
+
+namespace %s;
+
+class %s
+{
+}
+`, strings.TrimSuffix(namespace, "\\"), strings.TrimSuffix(component, ".php"))},
+	}
+
+	created := []string{}
+	for _, file := range files {
+		path := filepath.Join(dir, file.name)
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return created, fmt.Errorf("unable to create directory for %s: %w", file.name, err)
+		}
+		err = os.WriteFile(path, []byte(file.content), 0644)
+		if err != nil {
+			return created, fmt.Errorf("unable to create %s: %w", file.name, err)
+		}
+		created = append(created, filepath.Join("pkg", pkg, file.name))
+	}
+	return created, nil
+}
+
+// InitUpstreamRepository prepares the repository to push the first version
+// of a package to. A local repository is created as a bare repository.
+// Remote repositories must already exist (e.g. created on GitLab), but may
+// not have the branch yet.
+func InitUpstreamRepository(remote PackageRemote, branch string) error {
+	if isLocalPath(remote.Url) {
+		if _, err := os.Stat(remote.Url); os.IsNotExist(err) {
+			_, err := runGit(nil, "", "init", "--bare", "--initial-branch="+branch, "--", remote.Url)
+			if err != nil {
+				return fmt.Errorf("unable to create repository %s: %w", remote.Url, err)
+			}
+			return nil
+		}
+	}
+	heads, _, err := lsRemote(remote)
+	if err != nil {
+		return fmt.Errorf("unable to reach %s, please create the repository first: %w", remote.Url, err)
+	}
+	if _, ok := heads[branch]; ok {
+		return fmt.Errorf("branch %s already exists in %s, use pkg:pull to add the package instead", branch, remote.Url)
+	}
+	return nil
+}
+
+func isLocalPath(source string) bool {
+	return filepath.IsAbs(source) || strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
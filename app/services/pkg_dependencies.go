@@ -0,0 +1,296 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"src/config"
+	"strings"
+)
+
+// packageManifestFile lists the packages a package needs in pkg/, also
+// when they don't match a package source in config.json5.
+const packageManifestFile = "confetti.json"
+
+type PackageDependency struct {
+	Package    string
+	Constraint string
+	RequiredBy string
+}
+
+// PackageDependencies returns the packages that pkg requires and that are
+// managed in pkg/: all packages in confetti.json, and the packages in the
+// require of composer.json that match a package source or are already in pkg/.
+func PackageDependencies(pkg string) ([]PackageDependency, error) {
+	dir := filepath.Join(config.Path.Root, "pkg", pkg)
+	manifest, err := readRequire(filepath.Join(dir, packageManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	composer, err := readRequire(filepath.Join(dir, "composer.json"))
+	if err != nil {
+		return nil, err
+	}
+	sources, err := getPackageSources()
+	if err != nil {
+		return nil, err
+	}
+	lock, err := ReadPackageLock()
+	if err != nil {
+		return nil, err
+	}
+
+	requires := map[string]string{}
+	for dependency, constraint := range composer {
+		_, locked := lock.Packages[dependency]
+		_, inPkg := os.Stat(filepath.Join(config.Path.Root, "pkg", dependency))
+		if locked || inPkg == nil || matchesExplicitSource(sources, dependency) {
+			requires[dependency] = constraint
+		}
+	}
+	for dependency, constraint := range manifest {
+		requires[dependency] = constraint
+	}
+
+	dependencies := []PackageDependency{}
+	for _, dependency := range sortedKeys(stringKeys(requires)) {
+		// Platform requirements like php and ext-json are not packages
+		if !strings.Contains(dependency, "/") || dependency == pkg {
+			continue
+		}
+		// The name is used in paths and git commands
+		err := ValidatePackageName(dependency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dependency of %s: %w", pkg, err)
+		}
+		dependencies = append(dependencies, PackageDependency{
+			Package:    dependency,
+			Constraint: composerConstraint(requires[dependency]),
+			RequiredBy: pkg,
+		})
+	}
+	return dependencies, nil
+}
+
+func readRequire(file string) (map[string]string, error) {
+	content, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", file, err)
+	}
+	manifest := struct {
+		Require map[string]string `json:"require"`
+	}{}
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content in %s: %w", file, err)
+	}
+	return manifest.Require, nil
+}
+
+// matchesExplicitSource reports whether a source is configured for the
+// package. The catch-all source (* or without match) doesn't count, otherwise
+// all composer packages would be pulled into pkg/.
+func matchesExplicitSource(sources []PackageSourceConfig, pkg string) bool {
+	for _, source := range sources {
+		if source.Match != "" && source.Match != "*" && packageMatches(source.Match, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// composerConstraint converts a composer constraint to a constraint for
+// ResolvePackageVersion, e.g. dev-main is the branch main.
+func composerConstraint(constraint string) string {
+	constraint = strings.TrimSpace(constraint)
+	// Stability flags like ^1.0@dev are about composer, not about the version
+	if i := strings.Index(constraint, "@"); i > 0 {
+		constraint = constraint[:i]
+	}
+	if branch, found := strings.CutPrefix(constraint, "dev-"); found {
+		return branch
+	}
+	if constraint == "*" {
+		// Any version, so we use the default branch like pkg:pull without a version
+		return ""
+	}
+	return constraint
+}
+
+// LockedVersionSatisfies reports whether the locked version of a package matches the constraint.
+func LockedVersionSatisfies(locked LockedPackage, constraint string) bool {
+	if constraint == "" {
+		return true
+	}
+	if locked.Version == "" {
+		// A branch only satisfies the same branch
+		return locked.Ref == constraint
+	}
+	if locked.Version == constraint {
+		return true
+	}
+	version, ok := ParseSemVer(locked.Version)
+	if !ok {
+		return false
+	}
+	c, err := ParseVersionConstraint(constraint)
+	return err == nil && c.Matches(version)
+}
+
+// WalkPackageDependencies walks the dependencies of pkg depth first. pull is
+// called for every dependency that is not in pkg/ yet, or whose locked version
+// doesn't satisfy the constraint. Packages in visited are not walked again,
+// so a dependency that is required twice is pulled once. A dependency cycle
+// is not followed, but returned as e.g. [a/one a/two a/one].
+func WalkPackageDependencies(pkg string, visited map[string]bool, pull func(PackageDependency) error) ([][]string, error) {
+	cycles := [][]string{}
+	var walk func(pkg string, chain []string) error
+	walk = func(pkg string, chain []string) error {
+		visited[pkg] = true
+		dependencies, err := PackageDependencies(pkg)
+		if err != nil {
+			return err
+		}
+		for _, dependency := range dependencies {
+			if ContainsString(chain, dependency.Package) {
+				cycles = append(cycles, append(append([]string{}, chain...), dependency.Package))
+				continue
+			}
+			lock, err := ReadPackageLock()
+			if err != nil {
+				return err
+			}
+			locked, isLocked := lock.Packages[dependency.Package]
+			_, statErr := os.Stat(filepath.Join(config.Path.Root, "pkg", dependency.Package))
+			installed := statErr == nil
+			if installed && isLocked && !LockedVersionSatisfies(locked, dependency.Constraint) && visited[dependency.Package] {
+				return fmt.Errorf("%s requires %s %s, but %s is already locked at %s", dependency.RequiredBy, dependency.Package, dependency.Constraint, dependency.Package, lockedLabel(locked))
+			}
+			if !installed || (isLocked && !LockedVersionSatisfies(locked, dependency.Constraint)) {
+				// The version must also satisfy the packages that are already installed
+				requirements, err := installedRequirements(dependency)
+				if err != nil {
+					return err
+				}
+				constraints := []string{}
+				for _, requirement := range requirements {
+					constraints = append(constraints, requirement.Constraint)
+				}
+				combined, ok := CombineVersionConstraints(constraints...)
+				if !ok {
+					return fmt.Errorf("no version of %s satisfies %s", dependency.Package, requirementsLabel(requirements))
+				}
+				dependency.Constraint = combined
+				err = pull(dependency)
+				if err != nil {
+					return fmt.Errorf("unable to pull %s (required by %s): %w", dependency.Package, requirementsLabel(requirements), err)
+				}
+			}
+			if visited[dependency.Package] {
+				continue
+			}
+			err = walk(dependency.Package, append(append([]string{}, chain...), dependency.Package))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err := walk(pkg, []string{pkg})
+	return cycles, err
+}
+
+// installedRequirements returns the dependency together with the same
+// dependency of the other packages in pkg/, sorted by the requiring package.
+func installedRequirements(dependency PackageDependency) ([]PackageDependency, error) {
+	packages, err := ListPackages()
+	if err != nil {
+		return nil, err
+	}
+	requirements := []PackageDependency{dependency}
+	for _, pkg := range packages {
+		if pkg == dependency.Package || pkg == dependency.RequiredBy {
+			continue
+		}
+		dependencies, err := PackageDependencies(pkg)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range dependencies {
+			if other.Package == dependency.Package {
+				requirements = append(requirements, other)
+			}
+		}
+	}
+	sort.SliceStable(requirements, func(i, j int) bool { return requirements[i].RequiredBy < requirements[j].RequiredBy })
+	return requirements, nil
+}
+
+// requirementsLabel returns e.g. acme/blog ^2.0, acme/text ^2.1
+func requirementsLabel(requirements []PackageDependency) string {
+	labels := []string{}
+	for _, requirement := range requirements {
+		constraint := requirement.Constraint
+		if constraint == "" {
+			constraint = "*"
+		}
+		labels = append(labels, requirement.RequiredBy+" "+constraint)
+	}
+	return strings.Join(labels, ", ")
+}
+
+// CombineVersionConstraints returns a constraint that only matches the
+// versions that match all constraints, e.g. ^1.0 || ^2.0 and >=1.5 is
+// ^1.0 >=1.5 || ^2.0 >=1.5. An empty constraint matches everything. A
+// branch or tag can't be combined with a different constraint, then false
+// is returned.
+func CombineVersionConstraints(constraints ...string) (string, bool) {
+	alternatives := []string{""}
+	reference := ""
+	for _, constraint := range constraints {
+		constraint = strings.TrimSpace(constraint)
+		if constraint == "" || constraint == reference {
+			continue
+		}
+		_, err := ParseVersionConstraint(constraint)
+		if err != nil || reference != "" {
+			// A branch or tag only combines with itself
+			if reference != "" || alternatives[0] != "" {
+				return "", false
+			}
+			reference = constraint
+			continue
+		}
+		combined := []string{}
+		for _, alternative := range alternatives {
+			for _, part := range strings.Split(constraint, "||") {
+				combined = append(combined, strings.TrimSpace(alternative+" "+strings.TrimSpace(part)))
+			}
+		}
+		alternatives = combined
+	}
+	if reference != "" {
+		return reference, true
+	}
+	return strings.Join(alternatives, " || "), true
+}
+
+func lockedLabel(locked LockedPackage) string {
+	if locked.Version != "" {
+		return locked.Version
+	}
+	return locked.Ref
+}
+
+func stringKeys(m map[string]string) map[string]bool {
+	keys := map[string]bool{}
+	for key := range m {
+		keys[key] = true
+	}
+	return keys
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/config"
+)
+
+// PackageLockFile holds the resolved versions of the packages in pkg/. It
+// should be committed, so everyone uses the same versions.
+const PackageLockFile = "confetti.lock"
+
+type LockedPackage struct {
+	PackageVersion
+	Source string `json:"source"`
+}
+
+type PackageLock struct {
+	Packages map[string]LockedPackage `json:"packages"`
+}
+
+// ReadPackageLock returns the lockfile of the project, or an empty lock
+// when the project has no lockfile yet.
+func ReadPackageLock() (PackageLock, error) {
+	lock := PackageLock{Packages: map[string]LockedPackage{}}
+	content, err := os.ReadFile(filepath.Join(config.Path.Root, PackageLockFile))
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return lock, fmt.Errorf("unable to read %s: %w", PackageLockFile, err)
+	}
+	err = json.Unmarshal(content, &lock)
+	if err != nil {
+		return lock, fmt.Errorf("invalid content in %s: %w", PackageLockFile, err)
+	}
+	if lock.Packages == nil {
+		lock.Packages = map[string]LockedPackage{}
+	}
+	return lock, nil
+}
+
+// Write saves the lockfile and adds it to the git index.
+func (l PackageLock) Write() error {
+	// Encoding a map sorts the keys, so the diff only shows the changed packages
+	content, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode %s: %w", PackageLockFile, err)
+	}
+	err = os.WriteFile(filepath.Join(config.Path.Root, PackageLockFile), append(content, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("unable to write %s: %w", PackageLockFile, err)
+	}
+	_, err = runGit(nil, "", "add", "--", PackageLockFile)
+	if err != nil {
+		return fmt.Errorf("unable to add %s to git: %w", PackageLockFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"src/config"
+)
+
+// PackageOutdated compares the locked version of a package with the upstream versions.
+type PackageOutdated struct {
+	Package string
+	// Current is the locked tag, or the branch with the commit
+	Current string
+	// Wanted is the highest version within the constraint
+	Wanted string
+	// Latest is the highest version, also outside the constraint
+	Latest         string
+	VersionsBehind int
+	CommitsBehind  int
+}
+
+// CheckPackageOutdated fetches the upstream of the package and counts the
+// versions and commits that are not pulled yet.
+func CheckPackageOutdated(pkg string) (PackageOutdated, error) {
+	outdated := PackageOutdated{Package: pkg}
+	lock, err := ReadPackageLock()
+	if err != nil {
+		return outdated, err
+	}
+	locked := lock.Packages[pkg]
+	outdated.Current = locked.Version
+	if outdated.Current == "" {
+		outdated.Current = locked.Ref
+	}
+
+	remote, err := GetPackageRemote(pkg, locked.Source)
+	if err != nil {
+		return outdated, err
+	}
+	versions, err := ListPackageVersions(remote)
+	if err != nil {
+		return outdated, err
+	}
+	if len(versions) > 0 {
+		outdated.Latest = versions[len(versions)-1]
+	}
+	if current, ok := ParseSemVer(locked.Version); ok && locked.Version != "" {
+		for _, version := range versions {
+			v, _ := ParseSemVer(version)
+			if v.Compare(current) > 0 {
+				outdated.VersionsBehind++
+			}
+		}
+	}
+
+	wanted, err := ResolvePackageVersion(remote, locked.Constraint)
+	if err != nil {
+		return outdated, err
+	}
+	outdated.Wanted = wanted.Version
+	if outdated.Wanted == "" {
+		outdated.Wanted = wanted.Ref
+	}
+
+	status, err := GetPackageStatus(pkg, true)
+	if err != nil {
+		return outdated, err
+	}
+	outdated.CommitsBehind = len(status.UpstreamCommits)
+	if config.App.VeryVerbose {
+		fmt.Printf("Package %s is %d version(s) and %d commit(s) behind\n", pkg, outdated.VersionsBehind, outdated.CommitsBehind)
+	}
+	return outdated, nil
+}
+
+// ListPackageVersions returns the version tags (without prereleases) of a repository, from low to high.
+func ListPackageVersions(remote PackageRemote) ([]string, error) {
+	_, tags, err := lsRemote(remote)
+	if err != nil {
+		return nil, err
+	}
+	versions := []string{}
+	for tag := range tags {
+		if v, ok := ParseSemVer(tag); ok && v.Prerelease == "" {
+			versions = append(versions, tag)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		a, _ := ParseSemVer(versions[i])
+		b, _ := ParseSemVer(versions[j])
+		return a.Compare(b) < 0
+	})
+	return versions, nil
+}
+
+// RestoreProject undoes a pull that failed halfway. Pulls only start in a
+// project without modifications, so only changes of the pull are undone.
+func RestoreProject() error {
+	if MergeInProgress() {
+		return AbortPackageMerge()
+	}
+	_, err := runGit(nil, "", "reset", "--hard", "-q", "HEAD")
+	if err != nil {
+		return fmt.Errorf("unable to restore the project: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"src/config"
+	"strings"
+)
+
+// PackagePushPlan describes what a push of a package will do.
+type PackagePushPlan struct {
+	Package string
+	Branch  string
+	// SplitCommit is the history of pkg/<package> as a repository of its own
+	SplitCommit string
+	// RemoteCommit is the current commit of the branch, empty for a new branch
+	RemoteCommit string
+	// Commits will be pushed, as "<short hash> <subject>"
+	Commits []string
+	// FastForward is false when the branch has commits that are not pulled
+	FastForward bool
+}
+
+// PackageUncommittedChanges returns the changed and untracked files in the package.
+func PackageUncommittedChanges(pkg string) ([]string, error) {
+	out, err := runGit(nil, "", "status", "--porcelain", "--", "pkg/"+pkg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to check package %s for changes: %w", pkg, err)
+	}
+	return strings.FieldsFunc(out, func(r rune) bool { return r == '\n' }), nil
+}
+
+// splitCacheRef holds the last split of a package, splitSourceRef the
+// project commit it is split from.
+func splitCacheRef(pkg string) string  { return "refs/confetti/split/" + pkg }
+func splitSourceRef(pkg string) string { return "refs/confetti/split-source/" + pkg }
+
+// SplitPackage returns the history of pkg/<package> as a repository of its
+// own. Splitting the whole history is slow, so the result is cached in a ref:
+// when the package didn't change since the last split, the split is reused.
+// With rejoin, the split is merged back into the project, so the next split
+// only has to look at the commits after it.
+func SplitPackage(pkg string, rejoin bool) (string, error) {
+	head, err := runGit(nil, "", "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("unable to read the current commit: %w", err)
+	}
+	head = strings.TrimSpace(head)
+
+	if !rejoin {
+		cached, err1 := runGit(nil, "", "rev-parse", "--verify", "--quiet", splitCacheRef(pkg))
+		source, err2 := runGit(nil, "", "rev-parse", "--verify", "--quiet", splitSourceRef(pkg))
+		if err1 == nil && err2 == nil {
+			// Only commits in pkg/<package> change the split
+			source = strings.TrimSpace(source)
+			_, err := runGit(nil, "", "diff", "--quiet", source, head, "--", "pkg/"+pkg)
+			commits := ""
+			if err == nil {
+				commits, err = runGit(nil, "", "rev-list", source+".."+head, "--", "pkg/"+pkg)
+			}
+			if err == nil && strings.TrimSpace(commits) == "" {
+				if config.App.Verbose {
+					fmt.Printf("Package %s is not changed since the last split, using the cached split\n", pkg)
+				}
+				return strings.TrimSpace(cached), nil
+			}
+		}
+	}
+
+	split, err := subtreeSplit("pkg/" + pkg)
+	if err != nil {
+		return "", fmt.Errorf("unable to split package %s: %w", pkg, err)
+	}
+
+	if rejoin {
+		err = subtreeRejoin("pkg/"+pkg, split, "Split package "+pkg)
+		if err != nil {
+			return "", err
+		}
+		// The rejoin is a new commit in the project
+		head, err = runGit(nil, "", "rev-parse", "HEAD")
+		if err != nil {
+			return "", fmt.Errorf("unable to read the current commit: %w", err)
+		}
+		head = strings.TrimSpace(head)
+	}
+	_, err = runGit(nil, "", "update-ref", splitCacheRef(pkg), split)
+	if err == nil {
+		_, err = runGit(nil, "", "update-ref", splitSourceRef(pkg), head)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to cache the split of package %s: %w", pkg, err)
+	}
+	return split, nil
+}
+
+// PlanPackagePush splits the package and compares it with the branch upstream.
+func PlanPackagePush(pkg string, remote PackageRemote, branch string, rejoin bool) (PackagePushPlan, error) {
+	plan := PackagePushPlan{Package: pkg, Branch: branch, FastForward: true}
+	heads, _, err := lsRemote(remote)
+	if err != nil {
+		return plan, err
+	}
+	plan.RemoteCommit = heads[branch]
+
+	plan.SplitCommit, err = SplitPackage(pkg, rejoin)
+	if err != nil {
+		return plan, err
+	}
+
+	revisionRange := plan.SplitCommit
+	if plan.RemoteCommit != "" {
+		_, err = remote.runGit("fetch", "--no-tags", "--", remote.Url, branch)
+		if err != nil {
+			return plan, fmt.Errorf("unable to fetch branch %s of package %s: %w", branch, pkg, err)
+		}
+		_, err = runGit(nil, "", "merge-base", "--is-ancestor", plan.RemoteCommit, plan.SplitCommit)
+		plan.FastForward = err == nil
+		revisionRange = plan.RemoteCommit + ".." + plan.SplitCommit
+	}
+	plan.Commits, err = gitLogLines(revisionRange)
+	if err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// ExecutePackagePush pushes the split of the package to the branch. With
+// forceWithLease, the branch is only overwritten when it is still at the
+// commit the plan is based on.
+func ExecutePackagePush(remote PackageRemote, plan PackagePushPlan, forceWithLease bool) error {
+	if !plan.FastForward && !forceWithLease {
+		return fmt.Errorf("branch %s of package %s has commits that are not pulled, pull them first or use --force-with-lease", plan.Branch, plan.Package)
+	}
+	args := []string{"push"}
+	if forceWithLease {
+		args = append(args, fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", plan.Branch, plan.RemoteCommit))
+	}
+	args = append(args, "--", remote.Url, plan.SplitCommit+":refs/heads/"+plan.Branch)
+	output, err := remote.runGit(args...)
+	if config.App.Verbose {
+		fmt.Print(output)
+	}
+	if err != nil {
+		return fmt.Errorf("error pushing package %s to %s: %w", plan.Package, remote.Url, err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"src/config"
+	"strings"
+)
+
+// PackagePlaceholders can be used in the url of a package source.
+var PackagePlaceholders = []string{"__PACKAGE__", "__VENDOR__", "__NAME__"}
+
+// DefaultPackageHost is used when a source (or the project) doesn't configure a host.
+const DefaultPackageHost = "github.com"
+
+// PackageSourceConfig maps the packages that match a pattern (e.g. acme/*) to a repository.
+type PackageSourceConfig struct {
+	Match            string `json:"match"`
+	Url              string `json:"url"`
+	Protocol         string `json:"protocol"`
+	Host             string `json:"host"`
+	CredentialHelper string `json:"credential_helper"`
+}
+
+type PackagesConfig struct {
+	Sources []PackageSourceConfig `json:"sources"`
+}
+
+// urlTemplate returns the url of the source with placeholders. Without a
+// url, the url is built from the protocol (ssh by default) and the host.
+func (s PackageSourceConfig) urlTemplate() string {
+	if s.Url != "" {
+		return s.Url
+	}
+	host := s.Host
+	if host == "" {
+		host = DefaultPackageHost
+	}
+	if s.Protocol == "https" {
+		return "https://" + host + "/__PACKAGE__.git"
+	}
+	return "git@" + host + ":__PACKAGE__.git"
+}
+
+// PackageRemote is the repository of a package.
+type PackageRemote struct {
+	Url string
+	// CredentialHelper is added to the credential helpers of git, so
+	// credentials never have to be part of the url.
+	CredentialHelper string
+}
+
+// validate rejects a url that git would read as an option, e.g. --upload-pack=...
+func (r PackageRemote) validate() error {
+	if strings.HasPrefix(r.Url, "-") {
+		return fmt.Errorf("invalid repository %q, a repository can't start with -", r.Url)
+	}
+	return nil
+}
+
+// runGit runs git with the credential helper of the remote. The url and
+// refs are passed as arguments, they are never interpreted by a shell.
+func (r PackageRemote) runGit(args ...string) (string, error) {
+	err := r.validate()
+	if err != nil {
+		return "", err
+	}
+	if r.CredentialHelper != "" {
+		args = append([]string{"-c", "credential.helper=" + r.CredentialHelper}, args...)
+	}
+	return runGit(nil, "", args...)
+}
+
+// GetPackageRemote returns the repository of a package. The first source in
+// config.json5 that matches the package is used. Otherwise, the source in the
+// lockfile (e.g. from a pull with a full url) or GitHub is used.
+func GetPackageRemote(pkg string, lockedSource string) (PackageRemote, error) {
+	sources, err := getPackageSources()
+	if err != nil {
+		return PackageRemote{}, err
+	}
+	for _, source := range sources {
+		if !packageMatches(source.Match, pkg) {
+			continue
+		}
+		if config.App.VeryVerbose {
+			fmt.Printf("Package %s matches source %q\n", pkg, source.Match)
+		}
+		return PackageRemote{Url: packageUrl(source.urlTemplate(), pkg), CredentialHelper: source.CredentialHelper}, nil
+	}
+	if lockedSource != "" {
+		return PackageRemote{Url: lockedSource}, nil
+	}
+	return PackageRemote{Url: packageUrl(PackageSourceConfig{}.urlTemplate(), pkg)}, nil
+}
+
+// packageMatches reports whether the package matches a pattern like acme/*.
+// An empty pattern or * matches all packages.
+func packageMatches(pattern string, pkg string) bool {
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	ok, _ := path.Match(pattern, pkg)
+	return ok
+}
+
+func getPackageSources() ([]PackageSourceConfig, error) {
+	// Packages can be pulled in a project without config.json5
+	_, err := os.Stat(filepath.Join(config.Path.Root, configFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	appConfig, err := GetAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	return appConfig.Packages.Sources, nil
+}
+
+// packageUrl replaces the placeholders in the url. A relative path is relative to the project.
+func packageUrl(template string, pkg string) string {
+	vendor, name, _ := strings.Cut(pkg, "/")
+	result := strings.ReplaceAll(template, "__PACKAGE__", pkg)
+	result = strings.ReplaceAll(result, "__VENDOR__", vendor)
+	result = strings.ReplaceAll(result, "__NAME__", name)
+	if strings.HasPrefix(result, "./") || strings.HasPrefix(result, "../") {
+		return filepath.Join(config.Path.Root, result)
+	}
+	return result
+}
+
+var scpUrlRegex = regexp.MustCompile(`^[\w.-]+@[\w.-]+:`)
+
+// IsPackageUrl reports whether the argument is a repository instead of a
+// package name, e.g. https://gitlab.com/acme/blog.git, git@gitlab.com:acme/blog.git
+// or a local path.
+func IsPackageUrl(argument string) bool {
+	return strings.Contains(argument, "://") ||
+		scpUrlRegex.MatchString(argument) ||
+		strings.HasPrefix(argument, "/") ||
+		strings.HasPrefix(argument, "./") ||
+		strings.HasPrefix(argument, "../")
+}
+
+// PackageNameFromUrl returns the last two parts of the url, e.g. acme/blog
+// for https://gitlab.com/acme/blog.git. The name is used as the directory in
+// pkg/, so a url like ../acme/.. is rejected.
+func PackageNameFromUrl(source string) (string, error) {
+	trimmed := strings.TrimSuffix(strings.TrimRight(source, "/"), ".git")
+	parts := strings.FieldsFunc(trimmed, func(r rune) bool { return r == '/' || r == ':' || r == '\\' })
+	if len(parts) < 2 {
+		return "", fmt.Errorf("unable to get a package name (vendor/name) from %s", source)
+	}
+	name := parts[len(parts)-2] + "/" + parts[len(parts)-1]
+	err := ValidatePackageName(name)
+	if err != nil {
+		return "", fmt.Errorf("unable to get a package name from %s: %w", source, err)
+	}
+	return name, nil
+}
+
+// urlHasCredentials reports whether a password or token is part of the url.
+func urlHasCredentials(source string) bool {
+	if !strings.Contains(source, "://") {
+		return false
+	}
+	u, err := url.Parse(source)
+	if err != nil || u.User == nil {
+		return false
+	}
+	_, hasPassword := u.User.Password()
+	return hasPassword
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/config"
+	"strings"
+)
+
+// PackageStatus compares a package in pkg/ with its upstream repository.
+type PackageStatus struct {
+	Package string
+	Source  string
+	// Version is the locked tag or branch, empty when the package is not in the lockfile
+	Version string
+	// PullCommit is the commit in the project that added or pulled the package
+	PullCommit string
+	// PulledCommit is the upstream commit that is pulled
+	PulledCommit string
+	// LocalCommits changed the package since the last pull and are not pushed yet
+	LocalCommits []string
+	// UpstreamCommits are not pulled yet, nil when the upstream is not fetched
+	UpstreamCommits []string
+	UpstreamHead    string
+	Fetched         bool
+}
+
+// ListPackages returns the packages in pkg/, e.g. confetti-cms/text for pkg/confetti-cms/text.
+func ListPackages() ([]string, error) {
+	vendors, err := os.ReadDir(filepath.Join(config.Path.Root, "pkg"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the pkg directory: %w", err)
+	}
+	packages := []string{}
+	for _, vendor := range vendors {
+		if !vendor.IsDir() {
+			continue
+		}
+		names, err := os.ReadDir(filepath.Join(config.Path.Root, "pkg", vendor.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the pkg/%s directory: %w", vendor.Name(), err)
+		}
+		for _, name := range names {
+			if name.IsDir() {
+				packages = append(packages, vendor.Name()+"/"+name.Name())
+			}
+		}
+	}
+	return packages, nil
+}
+
+// LastSubtreeMerge returns the commit that added or pulled the package and
+// the upstream commit it brought in. The upstream commit is read from the
+// git-subtree-split metadata of the add and pull merges, or from the second
+// parent of a pull merge made before the metadata was added. Both are empty
+// when the package was never pulled.
+func LastSubtreeMerge(pkg string) (string, string, error) {
+	out, err := runGit(nil, "", "log", "--format=%H%x1f%P%x1f%B%x1e", "--grep=git-subtree-dir: pkg/"+pkg, "--grep=^Pull package "+pkg)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to find the last pull of package %s: %w", pkg, err)
+	}
+	for _, entry := range strings.Split(out, "\x1e") {
+		fields := strings.Split(strings.TrimSpace(entry), "\x1f")
+		if len(fields) != 3 {
+			continue
+		}
+		commit, parents, message := fields[0], strings.Fields(fields[1]), fields[2]
+		// The grep also matches packages that start with the same name
+		for _, line := range strings.Split(message, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "git-subtree-dir: pkg/"+pkg || line == "git-subtree-dir: pkg/"+pkg+"/" {
+				for _, line := range strings.Split(message, "\n") {
+					if split, found := strings.CutPrefix(strings.TrimSpace(line), "git-subtree-split: "); found {
+						return commit, split, nil
+					}
+				}
+			}
+		}
+		firstLine, _, _ := strings.Cut(message, "\n")
+		if (firstLine == "Pull package "+pkg || strings.HasPrefix(firstLine, "Pull package "+pkg+" ")) && len(parents) == 2 {
+			return commit, parents[1], nil
+		}
+	}
+	return "", "", nil
+}
+
+// GetPackageStatus compares the package with the upstream repository. With
+// fetch, the version of the lockfile is fetched to find the commits that are
+// not pulled yet.
+func GetPackageStatus(pkg string, fetch bool) (PackageStatus, error) {
+	status := PackageStatus{Package: pkg}
+	lock, err := ReadPackageLock()
+	if err != nil {
+		return status, err
+	}
+	locked := lock.Packages[pkg]
+	remote, err := GetPackageRemote(pkg, locked.Source)
+	if err != nil {
+		return status, err
+	}
+	status.Source = remote.Url
+	status.Version = locked.Version
+	if status.Version == "" {
+		status.Version = locked.Ref
+	}
+
+	status.PullCommit, status.PulledCommit, err = LastSubtreeMerge(pkg)
+	if err != nil {
+		return status, err
+	}
+	since := ""
+	if status.PullCommit != "" {
+		since = status.PullCommit + ".."
+	}
+	status.LocalCommits, err = gitLogLines(since+"HEAD", "--", "pkg/"+pkg)
+	if err != nil {
+		return status, err
+	}
+
+	if !fetch || status.PulledCommit == "" {
+		return status, nil
+	}
+	version, err := ResolvePackageVersion(remote, locked.Constraint)
+	if err != nil {
+		return status, err
+	}
+	_, err = remote.runGit("fetch", "--no-tags", "--", remote.Url, version.Ref)
+	if err != nil {
+		return status, fmt.Errorf("unable to fetch %s of package %s: %w", version.Ref, pkg, err)
+	}
+	status.Fetched = true
+	status.UpstreamHead = version.Commit
+	status.UpstreamCommits, err = gitLogLines(status.PulledCommit + ".." + version.Commit)
+	if err != nil {
+		return status, err
+	}
+
+	// After a push, the local commits are upstream, but not pulled yet
+	local, _ := runGit(nil, "", "rev-parse", "HEAD:pkg/"+pkg)
+	upstream, _ := runGit(nil, "", "rev-parse", version.Commit+"^{tree}")
+	if strings.TrimSpace(local) != "" && strings.TrimSpace(local) == strings.TrimSpace(upstream) {
+		status.LocalCommits = nil
+		status.UpstreamCommits = nil
+	}
+	return status, nil
+}
+
+// PackageDiffStat returns the files that are changed in the project and upstream since the last pull.
+func PackageDiffStat(status PackageStatus) (string, string, error) {
+	if status.PulledCommit == "" {
+		return "", "", nil
+	}
+	local, err := runGit(nil, "", "diff", "--stat", status.PulledCommit+"^{tree}", "HEAD:pkg/"+status.Package)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to compare package %s with the last pull: %w", status.Package, err)
+	}
+	if !status.Fetched || len(status.UpstreamCommits) == 0 {
+		return local, "", nil
+	}
+	upstream, err := runGit(nil, "", "diff", "--stat", status.PulledCommit, status.UpstreamHead)
+	if err != nil {
+		return local, "", fmt.Errorf("unable to compare package %s with upstream: %w", status.Package, err)
+	}
+	return local, upstream, nil
+}
+
+// gitLogLines returns the commits in the range as "<short hash> <subject>",
+// the range can be followed by -- and the paths.
+func gitLogLines(revisionRange ...string) ([]string, error) {
+	out, err := runGit(nil, "", append([]string{"log", "--format=%h %s"}, revisionRange...)...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the commits of %s: %w", strings.Join(revisionRange, " "), err)
+	}
+	return strings.FieldsFunc(out, func(r rune) bool { return r == '\n' }), nil
+}
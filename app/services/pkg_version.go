@@ -0,0 +1,358 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SemVer is a semantic version like v1.2.3 or 1.2.3-beta.1
+type SemVer struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+}
+
+// ParseSemVer accepts versions with or without the v prefix. A missing minor
+// or patch number is 0 (v1.2 is v1.2.0).
+func ParseSemVer(version string) (SemVer, bool) {
+	v := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	// Build metadata has no meaning for the order
+	v, _, _ = strings.Cut(v, "+")
+	v, prerelease, _ := strings.Cut(v, "-")
+	parts := strings.Split(v, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return SemVer{}, false
+	}
+	numbers := [3]int{}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return SemVer{}, false
+		}
+		numbers[i] = n
+	}
+	return SemVer{numbers[0], numbers[1], numbers[2], prerelease}, true
+}
+
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 when v is lower, equal or higher than o.
+func (v SemVer) Compare(o SemVer) int {
+	for _, diff := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if diff < 0 {
+			return -1
+		}
+		if diff > 0 {
+			return 1
+		}
+	}
+	switch {
+	case v.Prerelease == o.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case o.Prerelease == "":
+		return -1
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// comparePrerelease compares the dot separated identifiers one by one, like
+// SemVer: numbers by value (beta.2 < beta.10), numbers are lower than other
+// identifiers and a shorter list is lower when all identifiers are equal.
+func comparePrerelease(v, o string) int {
+	a, b := strings.Split(v, "."), strings.Split(o, ".")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		aNumber, aErr := strconv.ParseUint(a[i], 10, 64)
+		bNumber, bErr := strconv.ParseUint(b[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNumber != bNumber {
+				if aNumber < bNumber {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case a[i] != b[i]:
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// versionRange is a lower (inclusive) and upper (exclusive) bound, nil means unbounded
+type versionRange struct {
+	min *SemVer
+	max *SemVer
+	// exact is used for =1.2.3, so prereleases can be pinned
+	exact *SemVer
+}
+
+// VersionConstraint is a composer-like constraint, e.g. ^1.2, ~1.2.3, 1.x,
+// >=1.0 <2.0 or ^1.0 || ^2.0
+type VersionConstraint struct {
+	Original string
+	ranges   [][]versionRange
+}
+
+func ParseVersionConstraint(constraint string) (VersionConstraint, error) {
+	result := VersionConstraint{Original: constraint}
+	for _, alternative := range strings.Split(constraint, "||") {
+		all := []versionRange{}
+		for _, part := range strings.Fields(strings.ReplaceAll(alternative, ",", " ")) {
+			r, err := parseVersionRange(part)
+			if err != nil {
+				return result, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+			}
+			all = append(all, r)
+		}
+		if len(all) == 0 {
+			all = append(all, versionRange{})
+		}
+		result.ranges = append(result.ranges, all)
+	}
+	return result, nil
+}
+
+func parseVersionRange(part string) (versionRange, error) {
+	if part == "*" || part == "x" {
+		return versionRange{}, nil
+	}
+	for _, operator := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if !strings.HasPrefix(part, operator) {
+			continue
+		}
+		v, ok := ParseSemVer(strings.TrimPrefix(part, operator))
+		if !ok {
+			return versionRange{}, fmt.Errorf("%q is not a version", part)
+		}
+		numbers := strings.Count(strings.TrimPrefix(part, operator), ".") + 1
+		switch operator {
+		case ">=":
+			return versionRange{min: &v}, nil
+		case ">":
+			next := SemVer{v.Major, v.Minor, v.Patch + 1, ""}
+			return versionRange{min: &next}, nil
+		case "<":
+			return versionRange{max: &v}, nil
+		case "<=":
+			next := SemVer{v.Major, v.Minor, v.Patch + 1, ""}
+			return versionRange{max: &next}, nil
+		case "=":
+			return versionRange{exact: &v}, nil
+		case "^":
+			// The left-most non-zero number may not change
+			max := SemVer{v.Major + 1, 0, 0, ""}
+			if v.Major == 0 && numbers > 1 {
+				max = SemVer{0, v.Minor + 1, 0, ""}
+				if v.Minor == 0 && numbers > 2 {
+					max = SemVer{0, 0, v.Patch + 1, ""}
+				}
+			}
+			return versionRange{min: &v, max: &max}, nil
+		case "~":
+			// ~1.2.3 allows patches, ~1.2 allows minor versions
+			max := SemVer{v.Major, v.Minor + 1, 0, ""}
+			if numbers <= 2 {
+				max = SemVer{v.Major + 1, 0, 0, ""}
+			}
+			return versionRange{min: &v, max: &max}, nil
+		}
+	}
+	// 1.x, 1.2.* or a version without operator
+	trimmed := strings.TrimPrefix(part, "v")
+	if strings.HasSuffix(trimmed, ".x") || strings.HasSuffix(trimmed, ".*") {
+		prefix := strings.TrimSuffix(strings.TrimSuffix(trimmed, ".x"), ".*")
+		v, ok := ParseSemVer(prefix)
+		if !ok {
+			return versionRange{}, fmt.Errorf("%q is not a version", part)
+		}
+		max := SemVer{v.Major + 1, 0, 0, ""}
+		if strings.Contains(prefix, ".") {
+			max = SemVer{v.Major, v.Minor + 1, 0, ""}
+		}
+		return versionRange{min: &v, max: &max}, nil
+	}
+	v, ok := ParseSemVer(part)
+	if !ok {
+		return versionRange{}, fmt.Errorf("%q is not a version", part)
+	}
+	return versionRange{exact: &v}, nil
+}
+
+func (c VersionConstraint) Matches(v SemVer) bool {
+	for _, all := range c.ranges {
+		matches := true
+		for _, r := range all {
+			if !r.matches(v) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func (r versionRange) matches(v SemVer) bool {
+	if r.exact != nil {
+		return v.Compare(*r.exact) == 0
+	}
+	// Prereleases are only used when they are pinned
+	if v.Prerelease != "" {
+		return false
+	}
+	if r.min != nil && v.Compare(*r.min) < 0 {
+		return false
+	}
+	if r.max != nil && v.Compare(*r.max) >= 0 {
+		return false
+	}
+	return true
+}
+
+// PackageVersion is the result of resolving the requested version of a package.
+type PackageVersion struct {
+	// Constraint is what is requested, e.g. ^1.2, v1.2.3 or a branch
+	Constraint string `json:"constraint"`
+	// Version is the tag that matches the constraint, empty for a branch
+	Version string `json:"version,omitempty"`
+	// Ref is the tag or branch to pull
+	Ref    string `json:"ref"`
+	Commit string `json:"commit"`
+}
+
+// DefaultPackageBranch is used when no version is requested.
+const DefaultPackageBranch = "main"
+
+// ParsePackageArgument splits vendor/name@^1.2 into the package and the
+// requested version. The package can also be the url of the repository (e.g.
+// https://gitlab.com/acme/blog.git@^1.2), then the url is returned as source.
+func ParsePackageArgument(argument string) (pkg string, source string, requested string, err error) {
+	argument = strings.TrimSpace(argument)
+	pkg = argument
+	if i := strings.LastIndex(argument, "@"); i > 0 {
+		// The @ of git@github.com:acme/blog.git or https://user@host is not a version
+		before := argument[:i]
+		if _, withoutScheme, found := strings.Cut(before, "://"); found {
+			before = withoutScheme
+		}
+		if strings.ContainsAny(before, "/") && !strings.Contains(argument[i+1:], ":") {
+			pkg, requested = argument[:i], argument[i+1:]
+		}
+	}
+	if !IsPackageUrl(pkg) {
+		return pkg, "", requested, nil
+	}
+	if strings.HasPrefix(pkg, "-") {
+		return "", "", "", fmt.Errorf("invalid repository %q, a repository can't start with -", pkg)
+	}
+	if urlHasCredentials(pkg) {
+		return "", "", "", fmt.Errorf("don't put credentials in the url of %s, configure a git credential helper instead", pkg)
+	}
+	name, err := PackageNameFromUrl(pkg)
+	if err != nil {
+		return "", "", "", err
+	}
+	return name, pkg, requested, nil
+}
+
+// ResolvePackageVersion finds the commit of a branch, a tag or the highest
+// tag that matches a semver constraint in the remote repository.
+func ResolvePackageVersion(remote PackageRemote, requested string) (PackageVersion, error) {
+	source := remote.Url
+	heads, tags, err := lsRemote(remote)
+	if err != nil {
+		return PackageVersion{}, err
+	}
+	if requested == "" {
+		// Without the default branch, AddNewPackage explains how to create it
+		return PackageVersion{Constraint: DefaultPackageBranch, Ref: DefaultPackageBranch, Commit: heads[DefaultPackageBranch]}, nil
+	}
+	if commit, ok := heads[requested]; ok {
+		return PackageVersion{Constraint: requested, Ref: requested, Commit: commit}, nil
+	}
+	if commit, ok := tags[requested]; ok {
+		return PackageVersion{Constraint: requested, Version: requested, Ref: requested, Commit: commit}, nil
+	}
+
+	constraint, err := ParseVersionConstraint(requested)
+	if err != nil {
+		return PackageVersion{}, fmt.Errorf("%q is not a branch, tag or version constraint of %s: %w", requested, source, err)
+	}
+	best := ""
+	var bestVersion SemVer
+	available := []string{}
+	for tag := range tags {
+		v, ok := ParseSemVer(tag)
+		if !ok {
+			continue
+		}
+		available = append(available, tag)
+		if constraint.Matches(v) && (best == "" || v.Compare(bestVersion) > 0) {
+			best = tag
+			bestVersion = v
+		}
+	}
+	if best == "" {
+		sort.Strings(available)
+		if len(available) == 0 {
+			return PackageVersion{}, fmt.Errorf("no version of %s matches %s, the repository has no version tags", source, requested)
+		}
+		return PackageVersion{}, fmt.Errorf("no version of %s matches %s, available versions are %s", source, requested, strings.Join(available, ", "))
+	}
+	return PackageVersion{Constraint: requested, Version: best, Ref: best, Commit: tags[best]}, nil
+}
+
+// lsRemote returns the commits of the branches and tags of a remote repository.
+func lsRemote(remote PackageRemote) (map[string]string, map[string]string, error) {
+	out, err := remote.runGit("ls-remote", "--heads", "--tags", "--", remote.Url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read the versions of %s: %w", remote.Url, err)
+	}
+	heads := map[string]string{}
+	tags := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		commit, ref, found := strings.Cut(strings.TrimSpace(line), "\t")
+		if !found {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(ref, "refs/heads/"):
+			heads[strings.TrimPrefix(ref, "refs/heads/")] = commit
+		case strings.HasSuffix(ref, "^{}"):
+			// The commit of an annotated tag
+			tags[strings.TrimSuffix(strings.TrimPrefix(ref, "refs/tags/"), "^{}")] = commit
+		case strings.HasPrefix(ref, "refs/tags/"):
+			tag := strings.TrimPrefix(ref, "refs/tags/")
+			if _, ok := tags[tag]; !ok {
+				tags[tag] = commit
+			}
+		}
+	}
+	return heads, tags, nil
+}
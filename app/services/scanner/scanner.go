@@ -115,6 +115,14 @@ func (w Scanner) startListening(cli inter.Cli, watcher *fsnotify.Watcher, env se
 			if config.App.VeryVerbose {
 				log.Println("Modified file: ", event.Name, " Op:", event.Op)
 			}
+			// Keep the dependencies up to date when composer.lock changes (e.g. after a pull).
+			// Check it first, a pull can create or rename the file and parsing may stop the scanner.
+			if file == "composer.lock" {
+				err := services.ComposerInstallIfStale(cli, env)
+				if err != nil {
+					cli.Error(err.Error())
+				}
+			}
 			// Removed (by removing or renaming)
 			if eventIs(event, fsnotify.Rename) || eventIs(event, fsnotify.Remove) {
 				if config.App.VeryVerbose {
@@ -17,6 +17,9 @@ import (
 
 var UserError = errors.New("something went wrong, you can probably adjust it yourself to fix it")
 
+// NotFoundError is returned when the service responds with 404, e.g. for a component that doesn't exist
+var NotFoundError = errors.New("error with status: 404")
+
 var retry = 0
 
 func Send(cli inter.Cli, requestUrl string, body any, method string, env Environment, repo string, timeout time.Duration) (string, error) {
@@ -64,7 +67,7 @@ func Send(cli inter.Cli, requestUrl string, body any, method string, env Environ
 			fmt.Printf("\rOperation is taking longer than expected.                        ")
 		}
 
-		errStartDevContainers := startDevContainers(env, repo)
+		errStartDevContainers := startDevContainers(cli, env, repo)
 		if errStartDevContainers != nil {
 			if retry > 20 {
 				return "", fmt.Errorf("error starting dev containers: %w", errStartDevContainers)
@@ -97,6 +100,9 @@ func Send(cli inter.Cli, requestUrl string, body any, method string, env Environ
 			return string(responseBody), fmt.Errorf("%w: %s", UserError, title)
 		}
 		requestUrl, _ := url.QueryUnescape(requestUrl)
+		if res.StatusCode == http.StatusNotFound {
+			return string(responseBody), fmt.Errorf("%w with request: %s %s and response: %s", NotFoundError, method, requestUrl, string(responseBody))
+		}
 		err := fmt.Errorf(
 			"error with status: %d with request: %s %s and response: %s",
 			res.StatusCode, method, requestUrl, string(responseBody),
@@ -115,30 +121,9 @@ func debugRequest(method string, url string, payload string) {
 	}
 }
 
-func startDevContainers(env Environment, repository string) error {
-	jsonData := map[string]string{
-		"environment_name": env.Name,
-		"repository":       repository,
-	}
+func startDevContainers(cli inter.Cli, env Environment, repository string) error {
 	if config.App.VeryVerbose {
-		println("Start development POST : " + env.GetOrchestratorApi() + "/start_development with name " + env.Name + " and repository " + repository)
+		fmt.Println("Start development with name " + env.Name + " and repository " + repository)
 	}
-	jsonValue, _ := json.Marshal(jsonData)
-	response, err := http.Post(
-		env.GetOrchestratorApi()+"/start_development",
-		"application/json",
-		bytes.NewBuffer(jsonValue),
-	)
-	if err != nil {
-		bodyString := ""
-		if response != nil && response.Body != nil {
-			bodyBytes, _ := io.ReadAll(io.Reader(response.Body))
-			bodyString = string(bodyBytes)
-			response.Body.Close()
-		}
-		return fmt.Errorf("error: %v, response: %s", err, bodyString)
-	}
-	defer response.Body.Close()
-
-	return nil
+	return NewOrchestratorClient(cli, env).StartDevelopment(env.Name, repository)
 }
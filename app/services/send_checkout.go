@@ -14,7 +14,10 @@ type CheckoutBody struct {
 }
 
 func SendCheckout(cli inter.Cli, env Environment, requestBody CheckoutBody, repo string) error {
-	url := env.GetServiceUrl("confetti-cms/parser")
-	_, err := Send(cli, url+"/checkout", requestBody, http.MethodPut, env, repo, 30*time.Second)
+	url, err := env.GetServiceUrl("confetti-cms/parser")
+	if err != nil {
+		return err
+	}
+	_, err = Send(cli, url+"/checkout", requestBody, http.MethodPut, env, repo, 30*time.Second)
 	return err
 }
@@ -8,7 +8,10 @@ import (
 )
 
 func SendDeleteSource(cli inter.Cli, env Environment, path string, repo string) error {
-	url := env.GetServiceUrl("confetti-cms/parser")
-	_, err := Send(cli, url+"/source?path="+path, "", http.MethodDelete, env, repo, 30*time.Second)
+	url, err := env.GetServiceUrl("confetti-cms/parser")
+	if err != nil {
+		return err
+	}
+	_, err = Send(cli, url+"/source?path="+path, "", http.MethodDelete, env, repo, 30*time.Second)
 	return err
 }
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+// ParsedComponent is a component as the parser understands it.
+type ParsedComponent struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	// Extends is the chain of components it extends, the base component last
+	Extends []string               `json:"extends"`
+	Fields  []ParsedComponentField `json:"fields"`
+	Errors  []ParsedComponentError `json:"errors"`
+}
+
+type ParsedComponentField struct {
+	Key         string         `json:"key"`
+	Component   string         `json:"component"`
+	Decorations map[string]any `json:"decorations"`
+}
+
+type ParsedComponentError struct {
+	Message string `json:"message"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+type ListComponentsBody struct {
+	// Commit is empty for the components with the local changes
+	Commit string `json:"commit,omitempty"`
+}
+
+// GetParsedComponents returns all components. With a commit, the components
+// are returned as they were parsed at that commit.
+func GetParsedComponents(cli inter.Cli, env Environment, repo string, commit string) ([]ParsedComponent, error) {
+	url, err := env.GetServiceUrl("confetti-cms/parser")
+	if err != nil {
+		return nil, err
+	}
+	content, err := Send(cli, url+"/list_components", ListComponentsBody{Commit: commit}, http.MethodPost, env, repo, 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the parsed components: %w", err)
+	}
+	components := []ParsedComponent{}
+	err = json.Unmarshal([]byte(content), &components)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the parsed components: %w", err)
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+	return components, nil
+}
+
+// DecorationsString returns the decorations as JSON with sorted keys, e.g. {"label":"Title"}.
+func (f ParsedComponentField) DecorationsString() string {
+	if len(f.Decorations) == 0 {
+		return ""
+	}
+	content, err := json.Marshal(f.Decorations)
+	if err != nil {
+		return fmt.Sprint(f.Decorations)
+	}
+	return string(content)
+}
@@ -8,7 +8,10 @@ import (
 )
 
 func ParseBaseComponents(cli inter.Cli, env Environment, repo string) error {
-	url := env.GetServiceUrl("confetti-cms/parser")
-	_, err := Send(cli, url+"/parse_base_components", "", http.MethodPost, env, repo, 30*time.Second)
+	url, err := env.GetServiceUrl("confetti-cms/parser")
+	if err != nil {
+		return err
+	}
+	_, err = Send(cli, url+"/parse_base_components", "", http.MethodPost, env, repo, 30*time.Second)
 	return err
 }
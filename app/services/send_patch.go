@@ -117,8 +117,11 @@ func SendPatchE(cli inter.Cli, env Environment, path, patch string, repo string,
 	if config.App.VeryVeryVerbose {
 		println("Patch sending:", path)
 	}
-	url := env.GetServiceUrl("confetti-cms/parser")
-	_, err := Send(cli, url+"/source", body, http.MethodPatch, env, repo, timeout)
+	url, err := env.GetServiceUrl("confetti-cms/parser")
+	if err != nil {
+		return err
+	}
+	_, err = Send(cli, url+"/source", body, http.MethodPatch, env, repo, timeout)
 	return err
 }
 
@@ -137,12 +140,33 @@ func getBar(total int, description string, writer io.Writer) *progressbar.Progre
 		progressbar.OptionShowBytes(false),
 		progressbar.OptionSetWidth(30),
 		progressbar.OptionSetDescription(description),
-		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "[green]=[reset]",
-			SaucerHead:    "[green]|[reset]",
-			SaucerPadding: "-",
-			BarStart:      "|",
-			BarEnd:        "|",
-		}),
+		progressbar.OptionSetTheme(barTheme),
+	)
+}
+
+// getBytesBar is the getBar variant for downloads. A total of -1 shows a
+// spinner for when the size is unknown.
+func getBytesBar(total int64, description string, writer io.Writer) *progressbar.ProgressBar {
+	if config.App.VeryVerbose {
+		// AllTime progressbar in verbose mode
+		writer = io.Discard
+	}
+	return progressbar.NewOptions64(
+		total,
+		progressbar.OptionEnableColorCodes(true),
+		progressbar.OptionSetWriter(writer),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionSetWidth(30),
+		progressbar.OptionSetDescription(description),
+		progressbar.OptionSetTheme(barTheme),
+		progressbar.OptionThrottle(100*time.Millisecond),
 	)
 }
+
+var barTheme = progressbar.Theme{
+	Saucer:        "[green]=[reset]",
+	SaucerHead:    "[green]|[reset]",
+	SaucerPadding: "-",
+	BarStart:      "|",
+	BarEnd:        "|",
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/confetti-framework/framework/inter"
+)
+
+type ShowComponentBody struct {
+	Name string `json:"name"`
+	// Commit is empty for the component with the local changes
+	Commit string `json:"commit,omitempty"`
+}
+
+// GetParsedComponent returns one component by name, see GetParsedComponents.
+// The error wraps NotFoundError when the component doesn't exist (at the commit).
+func GetParsedComponent(cli inter.Cli, env Environment, repo string, name string, commit string) (ParsedComponent, error) {
+	url, err := env.GetServiceUrl("confetti-cms/parser")
+	if err != nil {
+		return ParsedComponent{}, err
+	}
+	content, err := Send(cli, url+"/show_component", ShowComponentBody{Name: name, Commit: commit}, http.MethodPost, env, repo, 30*time.Second)
+	if err != nil {
+		return ParsedComponent{}, fmt.Errorf("failed to fetch the parsed component %s: %w", name, err)
+	}
+	component := ParsedComponent{}
+	err = json.Unmarshal([]byte(content), &component)
+	if err != nil {
+		return ParsedComponent{}, fmt.Errorf("unable to decode the parsed component %s: %w", name, err)
+	}
+	return component, nil
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"src/config"
+	"strings"
+)
+
+// The subtree functions implement git subtree add, pull and split with git
+// plumbing. git subtree is a contrib script that is missing in some git
+// installations, and it exits with 1 when there is nothing to pull. The
+// metadata in the commit messages is the same, so the history of packages
+// that are added with git subtree can still be split.
+
+// runGit runs git in the project without a shell, so the arguments and the
+// input don't have to be escaped. The output is stdout, stderr is added to the error.
+func runGit(env []string, input string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = config.Path.Root
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	debugCommand("git "+strings.Join(args, " "), append(stdout.Bytes(), stderr.Bytes()...))
+	if err != nil {
+		return stdout.String(), fmt.Errorf("git %s: %v: %s", gitSubcommand(args), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// gitSubcommand returns the command after the -c options, e.g. fetch.
+func gitSubcommand(args []string) string {
+	for len(args) > 2 && args[0] == "-c" {
+		args = args[2:]
+	}
+	return args[0]
+}
+
+// fetchSubtree fetches the ref of the remote and returns the commit.
+func fetchSubtree(remote PackageRemote, ref string) (string, error) {
+	_, err := remote.runGit("fetch", "--no-tags", "--", remote.Url, ref)
+	if err != nil {
+		return "", err
+	}
+	commit, err := runGit(nil, "", "rev-parse", "--verify", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unable to read the fetched commit of %s %s: %w", remote.Url, ref, err)
+	}
+	return strings.TrimSpace(commit), nil
+}
+
+func headCommit() (string, error) {
+	head, err := runGit(nil, "", "rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("the project has no commits yet, commit the project first: %w", err)
+	}
+	return strings.TrimSpace(head), nil
+}
+
+// subtreeAdd adds the commit as the prefix directory with a merge commit, like git subtree add.
+func subtreeAdd(prefix, commit string) error {
+	head, err := headCommit()
+	if err != nil {
+		return err
+	}
+	files, err := runGit(nil, "", "ls-files", "--", prefix)
+	if err != nil {
+		return err
+	}
+	_, statErr := os.Stat(config.Path.Root + "/" + prefix)
+	if strings.TrimSpace(files) != "" || statErr == nil {
+		return fmt.Errorf("%s already exists", prefix)
+	}
+
+	_, err = runGit(nil, "", "read-tree", "--prefix="+prefix+"/", "-u", commit)
+	if err != nil {
+		return fmt.Errorf("unable to add %s: %w", prefix, err)
+	}
+	tree, err := runGit(nil, "", "write-tree")
+	if err != nil {
+		return err
+	}
+	message := fmt.Sprintf("Add '%s/' from commit '%s'\n\ngit-subtree-dir: %s\ngit-subtree-mainline: %s\ngit-subtree-split: %s\n", prefix, commit, prefix, head, commit)
+	merge, err := runGit(nil, message, "commit-tree", strings.TrimSpace(tree), "-p", head, "-p", commit)
+	if err != nil {
+		return fmt.Errorf("unable to commit %s: %w", prefix, err)
+	}
+	_, err = runGit(nil, "", "update-ref", "-m", "Add "+prefix, "HEAD", strings.TrimSpace(merge), head)
+	return err
+}
+
+// subtreeMerge merges the commit into the prefix directory with the subtree
+// strategy, like git subtree pull. It returns false when the commit is
+// already merged. On conflicts, the merge stays in progress.
+func subtreeMerge(prefix, commit, message string) (bool, error) {
+	_, err := runGit(nil, "", "merge-base", "--is-ancestor", commit, "HEAD")
+	if err == nil {
+		return false, nil
+	}
+	_, err = runGit(nil, "", "merge", "--no-ff", "--allow-unrelated-histories", "-Xsubtree="+prefix,
+		"-m", message, "-m", fmt.Sprintf("git-subtree-dir: %s\ngit-subtree-split: %s", prefix, commit), commit)
+	if err != nil {
+		return false, fmt.Errorf("unable to merge %s into %s: %w", commit, prefix, err)
+	}
+	return true, nil
+}
+
+// subtreeJoin is a commit in the project where the split history of the
+// prefix is known: the add, pull and rejoin commits.
+type subtreeJoin struct {
+	// Mainline is the project commit of the split, empty for a pull
+	Mainline string
+	Split    string
+}
+
+func subtreeJoins(prefix string) ([]subtreeJoin, error) {
+	out, err := runGit(nil, "", "log", "--format=%B%x1e", "--grep=git-subtree-dir: "+prefix, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the subtree history of %s: %w", prefix, err)
+	}
+	joins := []subtreeJoin{}
+	for _, message := range strings.Split(out, "\x1e") {
+		join := subtreeJoin{}
+		dir := ""
+		for _, line := range strings.Split(message, "\n") {
+			key, value, _ := strings.Cut(strings.TrimSpace(line), ": ")
+			switch key {
+			case "git-subtree-dir":
+				dir = strings.TrimSuffix(value, "/")
+			case "git-subtree-mainline":
+				join.Mainline = value
+			case "git-subtree-split":
+				join.Split = value
+			}
+		}
+		// The grep also matches directories that start with the same name
+		if dir == prefix && join.Split != "" {
+			joins = append(joins, join)
+		}
+	}
+	return joins, nil
+}
+
+type projectCommit struct {
+	Hash    string
+	Parents []string
+	Env     []string
+	Message string
+}
+
+// subtreeSplit returns the history of the prefix as a repository of its own,
+// like git subtree split. Every project commit is mapped to a split commit:
+// a commit that doesn't change the prefix is mapped to the split of its
+// parent, other commits are copied with the tree of the prefix. The author,
+// date and message are kept, so the split gives the same commits every time.
+func subtreeSplit(prefix string) (string, error) {
+	head, err := headCommit()
+	if err != nil {
+		return "", err
+	}
+	joins, err := subtreeJoins(prefix)
+	if err != nil {
+		return "", err
+	}
+	// mapped has the split commit of the project commits, trees the tree of the split commits
+	mapped := map[string]string{}
+	trees := map[string]string{}
+	args := []string{"log", "--topo-order", "--reverse", "--date=raw", "--format=%H%x1f%P%x1f%an%x1f%ae%x1f%ad%x1f%cn%x1f%ce%x1f%cd%x1f%B%x1e", "HEAD"}
+	batch := ""
+	for _, join := range joins {
+		// The history before a join is known, so it doesn't have to be split again
+		mapped[join.Split] = join.Split
+		args = append(args, "^"+join.Split)
+		if join.Mainline != "" {
+			mapped[join.Mainline] = join.Split
+			args = append(args, "^"+join.Mainline)
+		}
+		batch += join.Split + "^{tree}\n"
+	}
+	out, err := runGit(nil, "", args...)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the history of %s: %w", prefix, err)
+	}
+	commits := []projectCommit{}
+	for _, entry := range strings.Split(out, "\x1e") {
+		fields := strings.Split(strings.TrimLeft(entry, "\n"), "\x1f")
+		if len(fields) != 9 {
+			continue
+		}
+		commits = append(commits, projectCommit{
+			Hash:    fields[0],
+			Parents: strings.Fields(fields[1]),
+			Env: []string{
+				"GIT_AUTHOR_NAME=" + fields[2], "GIT_AUTHOR_EMAIL=" + fields[3], "GIT_AUTHOR_DATE=" + fields[4],
+				"GIT_COMMITTER_NAME=" + fields[5], "GIT_COMMITTER_EMAIL=" + fields[6], "GIT_COMMITTER_DATE=" + fields[7],
+			},
+			Message: fields[8],
+		})
+		batch += fields[0] + ":" + prefix + "\n"
+	}
+
+	// Read all trees at once, instead of a git command per commit
+	out, err = runGit(nil, batch, "cat-file", "--batch-check=%(objectname) %(objecttype)")
+	if err != nil {
+		return "", fmt.Errorf("unable to read the trees of %s: %w", prefix, err)
+	}
+	objects := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	object := func(i int) string {
+		if i >= len(objects) {
+			return ""
+		}
+		hash, objectType, _ := strings.Cut(objects[i], " ")
+		if objectType != "tree" {
+			// The commit has no prefix directory
+			return ""
+		}
+		return hash
+	}
+	for i, join := range joins {
+		trees[join.Split] = object(i)
+	}
+
+	for i, commit := range commits {
+		tree := object(len(joins) + i)
+		parents := []string{}
+		for _, parent := range commit.Parents {
+			if split := mapped[parent]; split != "" && !ContainsString(parents, split) {
+				parents = append(parents, split)
+			}
+		}
+		if tree == "" {
+			if len(parents) > 0 {
+				mapped[commit.Hash] = parents[0]
+			}
+			continue
+		}
+		identical, err := identicalSplitParent(tree, parents, trees)
+		if err != nil {
+			return "", err
+		}
+		if identical != "" {
+			mapped[commit.Hash] = identical
+			continue
+		}
+		args := []string{"commit-tree", tree}
+		for _, parent := range parents {
+			args = append(args, "-p", parent)
+		}
+		split, err := runGit(commit.Env, commit.Message, args...)
+		if err != nil {
+			return "", fmt.Errorf("unable to split commit %s of %s: %w", commit.Hash, prefix, err)
+		}
+		split = strings.TrimSpace(split)
+		mapped[commit.Hash] = split
+		trees[split] = tree
+	}
+
+	if mapped[head] == "" {
+		return "", fmt.Errorf("%s has no history to split", prefix)
+	}
+	return mapped[head], nil
+}
+
+// identicalSplitParent returns the parent that already has the tree, so the
+// commit doesn't change the prefix. A merge of a parent that is not in the
+// history of that parent still needs a commit of its own.
+func identicalSplitParent(tree string, parents []string, trees map[string]string) (string, error) {
+	for _, parent := range parents {
+		if trees[parent] != tree {
+			continue
+		}
+		for _, other := range parents {
+			if other == parent {
+				continue
+			}
+			_, err := runGit(nil, "", "merge-base", "--is-ancestor", other, parent)
+			if err != nil {
+				return "", nil
+			}
+		}
+		return parent, nil
+	}
+	return "", nil
+}
+
+// subtreeRejoin merges the split into the project, so the next split
+// only has to look at the commits after it.
+func subtreeRejoin(prefix, split, message string) error {
+	head, err := headCommit()
+	if err != nil {
+		return err
+	}
+	message = fmt.Sprintf("%s\n\ngit-subtree-dir: %s\ngit-subtree-mainline: %s\ngit-subtree-split: %s\n", message, prefix, head, split)
+	rejoin, err := runGit(nil, message, "commit-tree", "HEAD^{tree}", "-p", head, "-p", split)
+	if err != nil {
+		return fmt.Errorf("unable to rejoin the split of %s: %w", prefix, err)
+	}
+	_, err = runGit(nil, "", "update-ref", "-m", "Rejoin "+prefix, "HEAD", strings.TrimSpace(rejoin), head)
+	return err
+}
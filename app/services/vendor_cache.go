@@ -0,0 +1,203 @@
+package services
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"src/config"
+	"time"
+)
+
+const vendorCacheDir = "vendor"
+
+type CacheEntry struct {
+	Path     string
+	Size     int64
+	LastUsed time.Time
+}
+
+// VendorCacheKey returns the key of the vendor archive that belongs to the
+// current composer.lock. Without composer.lock, the key is empty and the
+// vendor directory can't be cached.
+func VendorCacheKey() (string, error) {
+	hash, err := ComposerLockHash()
+	if err != nil || hash == "" {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s-%s", hash, runtime.GOOS, runtime.GOARCH), nil
+}
+
+func vendorCachePath(key string) string {
+	return filepath.Join(config.Cache.Dir, vendorCacheDir, key+".zip")
+}
+
+// RestoreVendorFromCache unzips the cached vendor directory. It returns
+// false when there is nothing cached for the key.
+func RestoreVendorFromCache(key string) (bool, error) {
+	archive := vendorCachePath(key)
+	if _, err := os.Stat(archive); os.IsNotExist(err) {
+		if config.App.VeryVerbose {
+			fmt.Printf("No cached vendor directory found for %s\n", key)
+		}
+		return false, nil
+	}
+	vendor := filepath.Join(config.Path.Root, "vendor")
+	// Files of an old vendor directory should not stay behind
+	err := os.RemoveAll(vendor)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove old vendor directory: %w", err)
+	}
+	err = Unzip(archive, vendor)
+	if err != nil {
+		// Don't leave a half restored vendor directory behind
+		_ = os.RemoveAll(vendor)
+		_ = os.Remove(archive)
+		return false, fmt.Errorf("failed to restore vendor directory from cache, the cache entry is removed: %w", err)
+	}
+	// The modification time is used to prune the least recently used entries
+	now := time.Now()
+	_ = os.Chtimes(archive, now, now)
+	return true, nil
+}
+
+// StoreVendorInCache zips the vendor directory of the project in the cache.
+func StoreVendorInCache(key string) error {
+	vendor := filepath.Join(config.Path.Root, "vendor")
+	// Only cache a vendor directory that composer has finished
+	if _, err := os.Stat(filepath.Join(vendor, "autoload.php")); err != nil {
+		return fmt.Errorf("vendor directory is incomplete, it is not cached")
+	}
+	archive := vendorCachePath(key)
+	err := os.MkdirAll(filepath.Dir(archive), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	// Write to a temporary file first, so no other process can use a half written archive
+	tmp, err := os.CreateTemp(filepath.Dir(archive), key+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	err = zipDirectory(vendor, tmp)
+	closeErr := tmp.Close()
+	if err != nil {
+		return fmt.Errorf("failed to zip vendor directory: %w", err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write cache file: %w", closeErr)
+	}
+	err = os.Rename(tmp.Name(), archive)
+	if err != nil {
+		return fmt.Errorf("failed to store vendor directory in cache: %w", err)
+	}
+	if config.App.Verbose {
+		fmt.Printf("Vendor directory stored in cache: %s\n", archive)
+	}
+	return nil
+}
+
+func zipDirectory(dir string, target io.Writer) error {
+	w := zip.NewWriter(target)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		// Follow symlinks (e.g. vendor/bin), Unzip only creates regular files
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if d.Type()&fs.ModeSymlink != 0 {
+				return nil
+			}
+			_, err = w.Create(filepath.ToSlash(rel) + "/")
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		header.Method = zip.Deflate
+		writer, err := w.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(writer, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// GetCacheEntries returns all files in the cache, the least recently used first.
+func GetCacheEntries() ([]CacheEntry, error) {
+	entries := []CacheEntry{}
+	err := filepath.WalkDir(config.Cache.Dir, func(path string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, CacheEntry{Path: path, Size: info.Size(), LastUsed: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory %s: %w", config.Cache.Dir, err)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// PruneCache removes the least recently used files until the cache is not
+// larger than maxSize bytes. It returns the removed entries.
+func PruneCache(maxSize int64) ([]CacheEntry, error) {
+	entries, err := GetCacheEntries()
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+	removed := []CacheEntry{}
+	for _, entry := range entries {
+		if total <= maxSize {
+			break
+		}
+		err := os.Remove(entry.Path)
+		if err != nil {
+			return removed, fmt.Errorf("failed to remove %s from cache: %w", entry.Path, err)
+		}
+		total -= entry.Size
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
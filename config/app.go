@@ -19,7 +19,6 @@ var App = struct {
 	Verbose         bool
 	VeryVerbose     bool
 	VeryVeryVerbose bool
-	Local           bool
 	Timezone        *time.Location
 	Locale,
 	FallbackLocale,
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/confetti-framework/framework/support/env"
+)
+
+var Cache = struct {
+	Dir     string
+	MaxSize int64
+}{
+	/*
+		|--------------------------------------------------------------------------
+		| Cache Directory
+		|--------------------------------------------------------------------------
+		|
+		| The per-user directory where reusable files are stored, such as the
+		| vendor archives of composer. The cache is shared by all projects, so
+		| a fresh clone doesn't have to install the same dependencies again.
+		|
+	*/
+	Dir: env.StringOr("CONFETTI_CACHE_DIR", defaultCacheDir()),
+
+	/*
+		|--------------------------------------------------------------------------
+		| Maximum Cache Size
+		|--------------------------------------------------------------------------
+		|
+		| The size in megabytes that `conf cache:prune` reduces the cache to.
+		| The least recently used files are removed first.
+		|
+	*/
+	MaxSize: int64(env.IntOr("CONFETTI_CACHE_MAX_SIZE", 2048)),
+}
+
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "confetti")
+}
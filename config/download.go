@@ -0,0 +1,48 @@
+package config
+
+import (
+	"time"
+
+	"github.com/confetti-framework/framework/support/env"
+)
+
+var Download = struct {
+	Timeout           time.Duration
+	MinBytesPerSecond int64
+	MaxAttempts       int
+}{
+	/*
+		|--------------------------------------------------------------------------
+		| Download Timeout
+		|--------------------------------------------------------------------------
+		|
+		| The maximum time in seconds a single download attempt may take. When
+		| it is 0, the timeout is based on the size of the download, so large
+		| archives on slow connections still get the chance to finish.
+		|
+	*/
+	Timeout: time.Duration(env.IntOr("DOWNLOAD_TIMEOUT", 0)) * time.Second,
+
+	/*
+		|--------------------------------------------------------------------------
+		| Minimum Download Speed
+		|--------------------------------------------------------------------------
+		|
+		| Used to calculate the size-based timeout. The default (50 KB/s) is
+		| slow enough to tolerate bad connections while still giving up on a
+		| connection that has stalled completely.
+		|
+	*/
+	MinBytesPerSecond: int64(env.IntOr("DOWNLOAD_MIN_BYTES_PER_SECOND", 50*1024)),
+
+	/*
+		|--------------------------------------------------------------------------
+		| Download Attempts
+		|--------------------------------------------------------------------------
+		|
+		| A failed download is resumed where it stopped. This is the number
+		| of attempts before we give up.
+		|
+	*/
+	MaxAttempts: env.IntOr("DOWNLOAD_MAX_ATTEMPTS", 5),
+}
@@ -1,6 +1,15 @@
 package config
 
+import (
+	_ "embed"
+)
+
+//go:embed schema/config.schema.json
+var configSchema []byte
+
 // Embed contains fields of all resources to be loaded during compile time.
 var Embed = struct {
+	ConfigSchema []byte
 }{
+	ConfigSchema: configSchema,
 }
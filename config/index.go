@@ -10,8 +10,10 @@ package config
 	|
 */
 var Index = map[string]interface{}{
-	"App":   App,
-	"Auth0": Auth0,
-	"Embed": Embed,
-	"Path":  Path,
+	"App":      App,
+	"Auth0":    Auth0,
+	"Cache":    Cache,
+	"Download": Download,
+	"Embed":    Embed,
+	"Path":     Path,
 }
@@ -9,6 +9,7 @@ require (
 	github.com/spf13/cast v1.7.1
 	github.com/titanous/json5 v1.0.0
 	golang.org/x/text v0.24.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -27,8 +28,8 @@ require (
 	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
 	github.com/jackc/pgtype v1.14.4 // indirect
 	github.com/jackc/pgx/v4 v4.18.3 // indirect
-	github.com/jedib0t/go-pretty/v6 v6.6.7 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
+	github.com/jedib0t/go-pretty/v6 v6.6.7
+	github.com/joho/godotenv v1.5.1
 	github.com/manifoldco/promptui v0.9.0 // indirect
 	github.com/matryer/is v1.4.1
 	github.com/mattn/go-runewidth v0.0.16 // indirect
@@ -46,6 +47,6 @@ require (
 	golang.org/x/net v0.39.0
 	golang.org/x/sync v0.13.0 // indirect
 	golang.org/x/sys v0.32.0 // indirect
-	golang.org/x/term v0.31.0 // indirect
+	golang.org/x/term v0.31.0
 	golang.org/x/tools v0.32.0 // indirect
 )
@@ -9,10 +9,10 @@ import (
 
 func Test_no_changes(t *testing.T) {
 	// Given
-	dir := initTestGit()
+	initTestGit()
 
 	// When
-	changes := services.ChangedFilesSinceRemoteCommit(dir, "")
+	changes := services.ChangedFilesSinceRemoteCommit("")
 	// Then
 	i := is.New(t)
 	i.True(len(changes) == 0)
@@ -23,7 +23,7 @@ func Test_one_new_file(t *testing.T) {
 	dir := initTestGit()
 	touchFile(dir, "logo.svg")
 	// When
-	changes := services.ChangedFilesSinceRemoteCommit(dir, "")
+	changes := services.ChangedFilesSinceRemoteCommit("")
 	// Then
 	i := is.New(t)
 	i.True(len(changes) == 1)
@@ -36,7 +36,7 @@ func Test_multiple_new_files(t *testing.T) {
 	touchFile(dir, "logo.png")
 	touchFile(dir, "logo.svg")
 	// When
-	changes := services.ChangedFilesSinceRemoteCommit(dir, "")
+	changes := services.ChangedFilesSinceRemoteCommit("")
 	// Then
 	i := is.New(t)
 	i.True(len(changes) == 2)
@@ -49,7 +49,7 @@ func Test_new_path_with_file(t *testing.T) {
 	dir := initTestGit()
 	touchFile(dir, "images/logo.svg")
 	// When
-	changes := services.ChangedFilesSinceRemoteCommit(dir, "")
+	changes := services.ChangedFilesSinceRemoteCommit("")
 	// Then
 	i := is.New(t)
 	i.True(len(changes) == 1)
@@ -61,7 +61,7 @@ func Test_file_with_capital_letter(t *testing.T) {
 	dir := initTestGit()
 	touchFile(dir, "images/Logo.svg")
 	// When
-	changes := services.ChangedFilesSinceRemoteCommit(dir, "")
+	changes := services.ChangedFilesSinceRemoteCommit("")
 	// Then
 	i := is.New(t)
 	i.True(len(changes) == 1)
@@ -73,7 +73,7 @@ func Test_file_with_number(t *testing.T) {
 	dir := initTestGit()
 	touchFile(dir, "images/Logo2.svg")
 	// When
-	changes := services.ChangedFilesSinceRemoteCommit(dir, "")
+	changes := services.ChangedFilesSinceRemoteCommit("")
 	// Then
 	i := is.New(t)
 	i.True(len(changes) == 1)
@@ -85,7 +85,7 @@ func Test_file_with_special_caracters(t *testing.T) {
 	dir := initTestGit()
 	touchFile(dir, "images/Logo-_.svg")
 	// When
-	changes := services.ChangedFilesSinceRemoteCommit(dir, "")
+	changes := services.ChangedFilesSinceRemoteCommit("")
 	// Then
 	i := is.New(t)
 	i.True(len(changes) == 1)
@@ -98,7 +98,7 @@ func Test_status_staged_added(t *testing.T) {
 	touchFile(dir, "logo.svg")
 	gitAdd(dir, "logo.svg")
 	// When
-	changes := services.ChangedFilesSinceRemoteCommit(dir, "")
+	changes := services.ChangedFilesSinceRemoteCommit("")
 	// Then
 	i := is.New(t)
 	i.True(len(changes) == 1)
@@ -114,7 +114,7 @@ func Test_status_staged_modified(t *testing.T) {
 	setFileContent(dir, "logo.svg", "Content")
 	gitAdd(dir, "logo.svg")
 	// When
-	changes := services.ChangedFilesSinceRemoteCommit(dir, "")
+	changes := services.ChangedFilesSinceRemoteCommit("")
 	// Then
 	i := is.New(t)
 	i.True(len(changes) == 1)
@@ -131,7 +131,7 @@ func Test_status_staged_deleted(t *testing.T) {
 	deleteFile(dir, "logo.svg")
 	gitAdd(dir, "logo.svg")
 	// When
-	changes := services.ChangedFilesSinceRemoteCommit(dir, "")
+	changes := services.ChangedFilesSinceRemoteCommit("")
 	// Then
 	i := is.New(t)
 	i.True(len(changes) == 1)
@@ -151,7 +151,7 @@ func Test_status_staged_renamed(t *testing.T) {
 	gitAdd(dir, "logo1.svg") // Also add deleted file
 	gitAdd(dir, "logo2.svg")
 	// When
-	changes := services.ChangedFilesSinceRemoteCommit(dir, "")
+	changes := services.ChangedFilesSinceRemoteCommit("")
 	// Then
 	i := is.New(t)
 	i.True(len(changes) == 2)
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"src/app/services"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_diff_parsed_component_fields(t *testing.T) {
+	// Given
+	before := services.ParsedComponent{Name: "homepage", Extends: []string{"page"}, Fields: []services.ParsedComponentField{
+		{Key: "title", Component: "text", Decorations: map[string]any{"label": "Title"}},
+		{Key: "image", Component: "image"},
+		{Key: "intro", Component: "text"},
+	}}
+	after := services.ParsedComponent{Name: "homepage", Extends: []string{"page"}, Fields: []services.ParsedComponentField{
+		{Key: "title", Component: "text", Decorations: map[string]any{"label": "Title", "max": 80}},
+		{Key: "image", Component: "image"},
+		{Key: "body", Component: "content"},
+	}}
+	i := is.New(t)
+	// When
+	changes := services.DiffParsedComponent(before, after)
+	// Then
+	i.Equal(changes, []services.ComponentChange{
+		{Component: "homepage", Field: "title", Kind: services.ComponentChanged, Before: `text {"label":"Title"}`, After: `text {"label":"Title","max":80}`},
+		{Component: "homepage", Field: "body", Kind: services.ComponentAdded, After: "content"},
+		{Component: "homepage", Field: "intro", Kind: services.ComponentRemoved, Before: "text"},
+	})
+}
+
+func Test_diff_parsed_components_added_removed_and_extends(t *testing.T) {
+	// Given
+	before := []services.ParsedComponent{
+		{Name: "blog", File: "view/blog.blade.php", Extends: []string{"page"}},
+		{Name: "footer", File: "view/footer.blade.php"},
+	}
+	after := []services.ParsedComponent{
+		{Name: "blog", File: "view/blog.blade.php", Extends: []string{"article", "page"}},
+		{Name: "header", File: "view/header.blade.php"},
+	}
+	i := is.New(t)
+	// When
+	changes := services.DiffParsedComponents(before, after)
+	// Then
+	i.Equal(changes, []services.ComponentChange{
+		{Component: "blog", Kind: services.ComponentChanged, Before: "extends [page]", After: "extends [article page]"},
+		{Component: "footer", Kind: services.ComponentRemoved, Before: "view/footer.blade.php"},
+		{Component: "header", Kind: services.ComponentAdded, After: "view/header.blade.php"},
+	})
+}
+
+func Test_diff_parsed_component_that_is_new_since_the_remote_commit(t *testing.T) {
+	// Given
+	after := []services.ParsedComponent{{Name: "header", File: "view/header.blade.php", Fields: []services.ParsedComponentField{{Key: "logo", Component: "image"}}}}
+	i := is.New(t)
+	// When
+	changes := services.DiffParsedComponents(nil, after)
+	// Then
+	i.Equal(changes, []services.ComponentChange{
+		{Component: "header", Kind: services.ComponentAdded, After: "view/header.blade.php"},
+	})
+}
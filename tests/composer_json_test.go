@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"src/app/services"
+	"src/config"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_remove_package_from_composer_autoload(t *testing.T) {
+	// Given
+	config.Path.Root = t.TempDir()
+	content := `{
+    "name": "acme/website",
+    "require": {
+        "php": "^8.2",
+        "acme/blog": "*"
+    },
+    "autoload": {
+        "psr-4": {
+            "App\\": "app/",
+            "Acme\\Blog\\": "pkg/acme/blog/src/",
+            "Acme\\Shared\\": ["pkg/acme/blog/shared/", "shared/"]
+        },
+        "files": ["pkg/acme/blog/helpers.php", "helpers.php"]
+    },
+    "repositories": [
+        {"type": "path", "url": "pkg/acme/blog"},
+        {"type": "path", "url": "pkg/acme/blogger"}
+    ]
+}
+`
+	_ = os.WriteFile(filepath.Join(config.Path.Root, "composer.json"), []byte(content), 0644)
+	// When
+	required, err1 := services.ComposerRequires("acme/blog")
+	changed, err2 := services.RemoveComposerAutoload("acme/blog")
+	// Then
+	i := is.New(t)
+	i.NoErr(err1)
+	i.NoErr(err2)
+	i.True(required)
+	i.True(changed)
+	result, _ := os.ReadFile(filepath.Join(config.Path.Root, "composer.json"))
+	i.Equal(string(result), `{
+    "name": "acme/website",
+    "require": {
+        "php": "^8.2",
+        "acme/blog": "*"
+    },
+    "autoload": {
+        "psr-4": {
+            "App\\": "app/",
+            "Acme\\Shared\\": [
+                "shared/"
+            ]
+        },
+        "files": [
+            "helpers.php"
+        ]
+    },
+    "repositories": [
+        {
+            "type": "path",
+            "url": "pkg/acme/blogger"
+        }
+    ]
+}
+`)
+}
+
+func Test_remove_package_path_from_shared_namespace(t *testing.T) {
+	// Given
+	config.Path.Root = t.TempDir()
+	content := `{
+    "autoload": {
+        "psr-4": {
+            "App\\": ["src/", "pkg/acme/blog/src/"]
+        }
+    }
+}
+`
+	_ = os.WriteFile(filepath.Join(config.Path.Root, "composer.json"), []byte(content), 0644)
+	// When
+	changed, err := services.RemoveComposerAutoload("acme/blog")
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	i.True(changed)
+	result, _ := os.ReadFile(filepath.Join(config.Path.Root, "composer.json"))
+	i.Equal(string(result), `{
+    "autoload": {
+        "psr-4": {
+            "App\\": [
+                "src/"
+            ]
+        }
+    }
+}
+`)
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"src/app/services"
+	"src/config"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_config_environment_extends_other_environment(t *testing.T) {
+	// Given
+	setConfigFiles(t, `{environments: [
+  {name: "dev", containers: [{hosts: ["dev.example.com"]}, {name: "confetti-cms/parser", hosts: ["api.example.com"], port: 8080}]},
+  {name: "staging", extends: "dev", containers: [{name: "confetti-cms/parser", port: 9090}]},
+]}`, "", "")
+
+	// When
+	appConfig, err := services.GetAppConfig()
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	staging := appConfig.Environments[1]
+	i.Equal(staging.Name, "staging")
+	i.Equal(len(staging.Containers), 2)
+	i.Equal(staging.Containers[0].Hosts[0], "dev.example.com")
+	i.Equal(staging.Containers[1].Hosts[0], "api.example.com")
+	i.Equal(staging.Containers[1].Port, 9090)
+	i.Equal(appConfig.Environments[0].Containers[1].Port, 8080)
+}
+
+func Test_config_environments_extend_each_other(t *testing.T) {
+	// Given
+	content := `{environments: [
+  {name: "dev", extends: "staging", containers: [{hosts: ["example.com"]}]},
+  {name: "staging", extends: "dev"},
+]}`
+
+	// When
+	errs := services.ValidateAppConfig([]byte(content), nil)
+
+	// Then
+	i := is.New(t)
+	i.Equal(len(errs), 1)
+	i.Equal(errs[0].Error(), `config.json5:3:30: environments[1].extends: environments extend each other in a loop: dev -> staging -> dev`)
+}
+
+func Test_config_local_file_and_variables(t *testing.T) {
+	// Given
+	t.Setenv("CONFETTI_TEST_HOST", "env.example.com")
+	setConfigFiles(t,
+		`{environments: [{name: "dev", containers: [{hosts: ["${CONFETTI_TEST_HOST}"]}, {name: "confetti-cms/parser", hosts: ["${CONFETTI_TEST_API:-api.example.com}"]}]}]}`,
+		`{environments: [{name: "dev", local: true, containers: [{name: "confetti-cms/parser", hosts: ["${CONFETTI_TEST_DOTENV}"]}]}]}`,
+		"CONFETTI_TEST_DOTENV=dotenv.example.com\n",
+	)
+
+	// When
+	appConfig, err := services.GetAppConfig()
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	dev := appConfig.Environments[0]
+	i.True(dev.Local)
+	i.Equal(dev.Containers[0].Hosts[0], "env.example.com")
+	i.Equal(dev.Containers[1].Hosts[0], "dotenv.example.com")
+}
+
+func Test_config_with_missing_variable(t *testing.T) {
+	// Given
+	content := `{environments: [{name: "dev", containers: [{hosts: ["${CONFETTI_TEST_UNKNOWN}"]}]}]}`
+
+	// When
+	errs := services.ValidateAppConfig([]byte(content), nil)
+
+	// Then
+	i := is.New(t)
+	i.Equal(len(errs), 1)
+	i.Equal(errs[0].Error(), `config.json5:1:54: variable ${CONFETTI_TEST_UNKNOWN} is not set in the environment or in .env`)
+}
+
+func Test_config_variables_in_fields_with_a_pattern_or_type(t *testing.T) {
+	// Given
+	t.Setenv("CONFETTI_TEST_ORCHESTRATOR", "https://orchestrator.example.com")
+	t.Setenv("CONFETTI_TEST_PORT", "8080")
+	setConfigFiles(t, `{environments: [{name: "dev", orchestrator: "${CONFETTI_TEST_ORCHESTRATOR}", containers: [{hosts: ["example.com"], port: "${CONFETTI_TEST_PORT}"}]}]}`, "", "")
+
+	// When
+	appConfig, err := services.GetAppConfig()
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	i.Equal(appConfig.Environments[0].Orchestrator, "https://orchestrator.example.com")
+	i.Equal(appConfig.Environments[0].Containers[0].Port, 8080)
+}
+
+func Test_config_variable_with_invalid_value(t *testing.T) {
+	// Given
+	t.Setenv("CONFETTI_TEST_ORCHESTRATOR", "orchestrator.example.com")
+	content := `{environments: [{name: "dev", orchestrator: "${CONFETTI_TEST_ORCHESTRATOR}", containers: [{hosts: ["example.com"]}]}]}`
+
+	// When
+	errs := services.ValidateAppConfig([]byte(content), nil)
+
+	// Then
+	i := is.New(t)
+	i.Equal(len(errs), 1)
+	i.Equal(errs[0].Line, 1)
+	i.Equal(errs[0].Column, 45)
+}
+
+func setConfigFiles(t *testing.T, content, localContent, dotEnv string) {
+	root := t.TempDir() + string(os.PathSeparator)
+	previous := config.Path.Root
+	config.Path.Root = root
+	t.Cleanup(func() { config.Path.Root = previous })
+	_ = os.WriteFile(filepath.Join(root, "config.json5"), []byte(content), 0644)
+	if localContent != "" {
+		_ = os.WriteFile(filepath.Join(root, "config.local.json5"), []byte(localContent), 0644)
+	}
+	if dotEnv != "" {
+		_ = os.WriteFile(filepath.Join(root, ".env"), []byte(dotEnv), 0644)
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"src/app/services"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const validConfig = `{
+  // Comments are allowed
+  environments: [
+    {
+      name: 'dev',
+      local: true,
+      options: {dev_tools: true},
+      containers: [
+        {hosts: ["confetti-cms.localhost"]},
+        {name: "confetti-cms/parser", hosts: ["api.confetti-cms.localhost"], paths: ["/__SERVICE__"]},
+      ],
+    },
+  ],
+}`
+
+func Test_config_is_valid(t *testing.T) {
+	// When
+	errs := services.ValidateAppConfig([]byte(validConfig), nil)
+
+	// Then
+	i := is.New(t)
+	i.Equal(len(errs), 0)
+}
+
+func Test_config_with_typo_in_key(t *testing.T) {
+	// Given
+	content := `{
+  environments: [{
+    name: "dev",
+    options: {dev_tool: true},
+    containers: [{hosts: ["example.com"]}],
+  }],
+}`
+
+	// When
+	errs := services.ValidateAppConfig([]byte(content), nil)
+
+	// Then
+	i := is.New(t)
+	i.Equal(len(errs), 1)
+	i.Equal(errs[0].Line, 4)
+	i.Equal(errs[0].Column, 15)
+	i.Equal(errs[0].Error(), `config.json5:4:15: environments[0].options: unknown key "dev_tool", did you mean "dev_tools"?`)
+}
+
+func Test_config_with_empty_hosts(t *testing.T) {
+	// Given
+	content := `{environments: [{name: "dev", containers: [{hosts: []}]}]}`
+
+	// When
+	errs := services.ValidateAppConfig([]byte(content), nil)
+
+	// Then
+	i := is.New(t)
+	i.Equal(len(errs), 1)
+	i.Equal(errs[0].Path, "environments[0].containers[0].hosts")
+}
+
+func Test_config_with_syntax_error(t *testing.T) {
+	// Given
+	content := "{\n  environments: [\n    {name: dev}\n  ]\n}"
+
+	// When
+	errs := services.ValidateAppConfig([]byte(content), nil)
+
+	// Then
+	i := is.New(t)
+	i.Equal(len(errs), 1)
+	i.Equal(errs[0].Line, 3)
+	i.Equal(errs[0].Column, 12)
+}
+
+func Test_config_semantic_rules(t *testing.T) {
+	// Given
+	content := `{
+  environments: [
+    {name: "dev", containers: [{name: "confetti-cms/parser", hosts: ["https://example.com"], paths: ["/__UNKNOWN__"]}]},
+    {name: "dev", containers: [{hosts: ["example.com"]}]},
+  ],
+}`
+
+	// When
+	errs := services.ValidateAppConfig([]byte(content), nil)
+
+	// Then
+	i := is.New(t)
+	messages := []string{}
+	for _, err := range errs {
+		messages = append(messages, err.Path)
+	}
+	i.Equal(messages, []string{
+		"environments[0].containers[0].hosts[0]",
+		"environments[0].containers[0].paths[0]",
+		"environments[0].containers",
+		"environments[1].name",
+	})
+}
+
+func Test_config_with_unknown_auth_key(t *testing.T) {
+	// Given
+	content := `{environments: [{name: "dev", auth: {domain: "example.eu.auth0.com", audiance: "api"}}]}`
+
+	// When
+	errs := services.ValidateAppConfig([]byte(content), nil)
+
+	// Then
+	i := is.New(t)
+	i.Equal(len(errs), 1)
+	i.Equal(errs[0].Error(), `config.json5:1:70: environments[0].auth: unknown key "audiance", did you mean "audience"?`)
+}
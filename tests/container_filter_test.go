@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"src/app/services"
+	"src/app/services/orchestrator"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_filter_containers_by_status_and_stage(t *testing.T) {
+	// Given
+	containers := containersFixture(t)
+
+	// When
+	filtered := services.FilterContainers(containers, services.ContainerFilter{
+		Statuses: []string{"running"},
+		Stages:   []string{"production"},
+	})
+
+	// Then
+	i := is.New(t)
+	i.Equal(len(filtered), 2)
+	i.Equal(filtered[0].ID, "prod-website-id")
+	i.Equal(filtered[1].ID, "prod-sharpening-id")
+}
+
+func Test_filter_containers_by_name(t *testing.T) {
+	// Given
+	containers := containersFixture(t)
+
+	// When
+	filtered := services.FilterContainers(containers, services.ContainerFilter{Names: []string{"sharpening-service"}})
+
+	// Then
+	i := is.New(t)
+	i.Equal(len(filtered), 2)
+	i.Equal(filtered[0].Status, "stopped")
+}
+
+func containersFixture(t *testing.T) []orchestrator.ContainerInformation {
+	content, err := os.ReadFile(path.Join(testsDir, "fixtures", "containers.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	containers := []orchestrator.ContainerInformation{}
+	err = json.Unmarshal(content, &containers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return containers
+}
+
+func Test_malformed_container_without_umbrella(t *testing.T) {
+	// Given
+	container := containersFixture(t)[0]
+	container.UmbrellaOrganization = ""
+	container.UmbrellaRepository = ""
+
+	// When
+	reason := services.MalformedContainerReason(container)
+
+	// Then
+	i := is.New(t)
+	i.Equal(reason, "no umbrella organization, umbrella repository")
+	i.Equal(services.MalformedContainerReason(containersFixture(t)[0]), "")
+}
@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"src/app/services"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func Test_container_status_tracker_remembers_transitions(t *testing.T) {
+	// Given
+	containers := containersFixture(t)[:2]
+	tracker := services.NewContainerStatusTracker()
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	tracker.Update(containers, start)
+
+	// When
+	containers[1].Status = "running"
+	tracked := tracker.Update(containers, start.Add(time.Minute))
+
+	// Then
+	i := is.New(t)
+	i.Equal(tracked[0].Since, start)
+	i.True(tracked[0].ChangedAt.IsZero())
+	i.Equal(tracked[1].PreviousStatus, "stopped")
+	i.Equal(tracked[1].Since, start.Add(time.Minute))
+	i.Equal(tracked[1].ChangedAt, start.Add(time.Minute))
+}
+
+func Test_container_status_tracker_uses_the_start_of_a_running_container(t *testing.T) {
+	// Given
+	containers := containersFixture(t)[:1]
+	containers[0].Status = "running"
+	containers[0].StartedAt = "2024-01-02T14:00:00Z"
+	tracker := services.NewContainerStatusTracker()
+	now := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	// When
+	tracked := tracker.Update(containers, now)
+
+	// Then
+	i := is.New(t)
+	i.Equal(tracked[0].Since, time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC))
+}
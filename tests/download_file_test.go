@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"src/app/services"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func Test_download_file_resumes_after_interruption(t *testing.T) {
+	// Given
+	content := bytes.Repeat([]byte("confetti"), 4096)
+	sum := sha256.Sum256(content)
+	requests := 0
+	ranges := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Repr-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":")
+		w.Header().Set("ETag", `"v1"`)
+		if requests == 2 {
+			ranges = r.Header.Get("Range")
+		}
+		if requests == 1 {
+			// Break the connection halfway
+			w.Header().Set("Content-Length", "32768")
+			_, _ = w.Write(content[:1000])
+			return
+		}
+		http.ServeContent(w, r, "vendor.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	// When
+	file, err := services.DownloadFile(io.Discard, server.URL+"/resume", "token")
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	defer os.Remove(file)
+	result, _ := os.ReadFile(file)
+	i.Equal(2, requests)
+	i.Equal(ranges, "bytes=1000-")
+	i.True(bytes.Equal(content, result))
+}
+
+func Test_download_file_restarts_when_the_file_changed(t *testing.T) {
+	// Given
+	old := bytes.Repeat([]byte("old"), 4096)
+	content := bytes.Repeat([]byte("new"), 4096)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			// Break the connection halfway of the old file
+			w.Header().Set("ETag", `"old"`)
+			w.Header().Set("Content-Length", "12288")
+			_, _ = w.Write(old[:1000])
+			return
+		}
+		w.Header().Set("ETag", `"new"`)
+		http.ServeContent(w, r, "vendor.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	// When
+	file, err := services.DownloadFile(io.Discard, server.URL+"/changed", "token")
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	defer os.Remove(file)
+	result, _ := os.ReadFile(file)
+	i.Equal(2, requests)
+	i.True(bytes.Equal(content, result))
+}
+
+func Test_download_file_with_wrong_digest(t *testing.T) {
+	// Given
+	sum := sha256.Sum256([]byte("other content"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]))
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	// When
+	_, err := services.DownloadFile(io.Discard, server.URL+"/wrong_digest", "token")
+
+	// Then
+	i := is.New(t)
+	i.True(err != nil)
+}
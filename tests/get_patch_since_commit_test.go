@@ -8,17 +8,6 @@ import (
 	"github.com/matryer/is"
 )
 
-func Test_patch_unkown_file(t *testing.T) {
-	// Given
-	dir := initTestGit()
-	// When
-	patch, err := services.GetPatchSinceCommitE("", dir, "unkown.md", true)
-	// Then
-	i := is.New(t)
-	i.Equal(patch, "")
-	i.NoErr(err)
-}
-
 func Test_patch_line_changed(t *testing.T) {
 	// Given
 	dir := initTestGit()
@@ -28,7 +17,7 @@ func Test_patch_line_changed(t *testing.T) {
 	gitCommit(dir, file)
 	setFileContent(dir, file, "<?php")
 	// When
-	patch, err := services.GetPatchSinceCommitE("", dir, file, true)
+	patch, err := services.GetPatchSinceCommitE("", file, true)
 	// Then
 	i := is.New(t)
 	i.NoErr(err)
@@ -51,7 +40,7 @@ func Test_patch_from_specific_commit(t *testing.T) {
 	setFileContent(dir, file, "second_")
 	since := getCommitFromLog(dir, 1)
 	// When
-	patch, err := services.GetPatchSinceCommitE(since, dir, file, true)
+	patch, err := services.GetPatchSinceCommitE(since, file, true)
 	// Then
 	i := is.New(t)
 	i.NoErr(err)
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"src/app/services"
+	"src/config"
 	"strings"
 
 	"github.com/spf13/cast"
@@ -16,16 +17,15 @@ import (
 
 const mockDir = "mock_generated"
 
+// testsDir is captured once, because initTestGit changes the working directory.
+var testsDir, _ = os.Getwd()
+
 func initTestGit() string {
 	pc, _, _, _ := runtime.Caller(1)
 	testDir := strings.Split(runtime.FuncForPC(pc).Name(), ".")[1]
-	currentDir, err := os.Getwd()
-	if err != nil {
-		log.Println(err)
-	}
-	dir := path.Join(currentDir, mockDir, testDir)
+	dir := path.Join(testsDir, mockDir, testDir)
 	// Clean up directory from old test
-	_, err = os.Stat(dir)
+	_, err := os.Stat(dir)
 	if !os.IsNotExist(err) {
 		err := os.RemoveAll(dir)
 		if err != nil {
@@ -47,6 +47,7 @@ func initTestGit() string {
 	if err != nil {
 		log.Fatal(err)
 	}
+	config.Path.Root = dir + config.App.LineSeparator
 	return dir
 }
 
@@ -111,3 +112,12 @@ func getCommitFromLog(dir string, since int) string {
 	}
 	return strings.Trim(raw, "\n")
 }
+
+func gitTag(dir string, tags ...string) {
+	for _, tag := range tags {
+		_, err := services.RunCommand(fmt.Sprintf(`cd %s && git tag %s`, dir, tag))
+		if err != nil {
+			log.Fatalf("failed to run `git tag`: %s", err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"src/app/services"
+	"src/app/services/orchestrator"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func Test_orchestrator_from_config(t *testing.T) {
+	// Given
+	env := services.Environment{Name: "staging", Local: true, Orchestrator: "https://orchestrator.example.com/api/"}
+
+	// When
+	client := services.NewOrchestratorClient(nil, env)
+
+	// Then
+	i := is.New(t)
+	i.Equal(client.Url("/container_list"), "https://orchestrator.example.com/api/container_list")
+}
+
+func Test_orchestrator_default(t *testing.T) {
+	i := is.New(t)
+	i.Equal(services.Environment{}.GetOrchestratorApi(), services.OrchestratorApiDefault)
+	i.Equal(services.Environment{Local: true}.GetOrchestratorApi(), services.OrchestratorApiLocalhost)
+}
+
+func Test_orchestrator_without_protocol_is_invalid(t *testing.T) {
+	// Given
+	content := `{environments: [{name: "dev", orchestrator: "orchestrator.example.com", containers: [{hosts: ["example.com"]}]}]}`
+
+	// When
+	errs := services.ValidateAppConfig([]byte(content), nil)
+
+	// Then
+	i := is.New(t)
+	i.Equal(len(errs), 1)
+	i.Equal(errs[0].Path, "environments[0].orchestrator")
+}
+
+func Test_orchestrator_list_containers(t *testing.T) {
+	// Given
+	i := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i.Equal(r.URL.Path, "/orchestrator/container_list")
+		i.Equal(r.URL.Query().Get("environment"), "dev")
+		i.Equal(r.Header.Get("Authorization"), "Bearer secret")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+			{"id": "1", "name": "website", "status": "running", "environment": map[string]string{"name": "dev", "stage": "development"}},
+		}})
+	}))
+	defer server.Close()
+	client := orchestrator.NewClient(server.URL+"/orchestrator/", func() (string, error) { return "secret", nil })
+
+	// When
+	containers, err := client.ListContainers(orchestrator.QueryContainerOptions{Environment: "dev"})
+
+	// Then
+	i.NoErr(err)
+	i.Equal(len(containers), 1)
+	i.Equal(containers[0].Name, "website")
+	i.Equal(containers[0].Environment.Stage, "development")
+}
+
+func Test_orchestrator_error_response(t *testing.T) {
+	// Given
+	i := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i.Equal(r.Method, http.MethodPost)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors": [{"title": "Repository not found"}]}`))
+	}))
+	defer server.Close()
+	client := orchestrator.NewClient(server.URL, nil)
+
+	// When
+	err := client.StartDevelopment("dev", "agency/website")
+
+	// Then
+	i.True(orchestrator.IsNotFound(err))
+	i.Equal(err.Error(), "orchestrator responded with status 404: Repository not found")
+}
+
+func Test_orchestrator_follow_logs_reconnects(t *testing.T) {
+	// Given
+	i := is.New(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		i.Equal(r.URL.Path, "/container_logs")
+		i.Equal(r.URL.Query().Get("follow"), "1")
+		if requests == 1 {
+			_, _ = w.Write([]byte(`{"stream": "stdout", "time": "2024-01-02T15:04:05Z", "message": "first"}` + "\n"))
+			return
+		}
+		i.Equal(r.URL.Query().Get("since"), "2024-01-02T15:04:05Z")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	client := orchestrator.NewClient(server.URL, nil)
+	messages := []string{}
+
+	// When
+	err := client.FollowLogs("website-id", orchestrator.LogOptions{}, func(line orchestrator.LogLine) error {
+		messages = append(messages, line.ContainerID+": "+line.Message)
+		return nil
+	})
+
+	// Then
+	i.True(orchestrator.IsNotFound(err))
+	i.Equal(messages, []string{"website-id: first"})
+	i.Equal(requests, 2)
+}
+
+func Test_orchestrator_follow_logs_keeps_lines_with_the_same_time(t *testing.T) {
+	// Given
+	i := is.New(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			_, _ = w.Write([]byte(`{"stream": "stdout", "time": "2024-01-02T15:04:05Z", "message": "first"}` + "\n"))
+			return
+		}
+		if requests == 2 {
+			i.Equal(r.URL.Query().Get("since"), "2024-01-02T15:04:05Z")
+			_, _ = w.Write([]byte(`{"stream": "stdout", "time": "2024-01-02T15:04:05Z", "message": "first"}` + "\n"))
+			_, _ = w.Write([]byte(`{"stream": "stdout", "time": "2024-01-02T15:04:05Z", "message": "second"}` + "\n"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	client := orchestrator.NewClient(server.URL, nil)
+	messages := []string{}
+
+	// When
+	err := client.FollowLogs("website-id", orchestrator.LogOptions{}, func(line orchestrator.LogLine) error {
+		messages = append(messages, line.Message)
+		return nil
+	})
+
+	// Then
+	i.True(orchestrator.IsNotFound(err))
+	i.Equal(messages, []string{"first", "second"})
+	i.Equal(requests, 3)
+}
+
+func Test_orchestrator_exec_returns_exit_code(t *testing.T) {
+	// Given
+	i := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := orchestrator.ExecRequest{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		i.Equal(request.Command, []string{"php", "-v"})
+		_, _ = w.Write([]byte(`{"stream": "stdout", "data": "PHP 8.3\n"}` + "\n"))
+		_, _ = w.Write([]byte(`{"stream": "stderr", "data": "warning\n", "exit_code": 3}` + "\n"))
+	}))
+	defer server.Close()
+	client := orchestrator.NewClient(server.URL, nil)
+	stdout, stderr := &strings.Builder{}, &strings.Builder{}
+
+	// When
+	exitCode, err := client.Exec("website-id", []string{"php", "-v"}, stdout, stderr)
+
+	// Then
+	i.NoErr(err)
+	i.Equal(exitCode, 3)
+	i.Equal(stdout.String(), "PHP 8.3\n")
+	i.Equal(stderr.String(), "warning\n")
+}
+
+func Test_orchestrator_wait_for_status(t *testing.T) {
+	// Given
+	statuses := []string{"stopped", "starting", "starting", "running"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[0]
+		statuses = statuses[1:]
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"id": r.URL.Query().Get("id"), "status": status}})
+	}))
+	defer server.Close()
+	client := orchestrator.NewClient(server.URL, nil)
+	reported := []string{}
+
+	// When
+	container, err := client.WaitForStatus("website-id", []string{"running"}, time.Second, time.Millisecond, func(status string) {
+		reported = append(reported, status)
+	})
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	i.Equal(container.Status, "running")
+	i.Equal(reported, []string{"stopped", "starting", "running"})
+}
+
+func Test_orchestrator_wait_for_restart(t *testing.T) {
+	// Given
+	responses := []map[string]any{
+		{"status": "running", "started_at": "2026-01-01T10:00:00Z"},
+		{"status": "running", "started_at": "2026-01-01T10:05:00Z"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := responses[0]
+		responses = responses[1:]
+		response["id"] = r.URL.Query().Get("id")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": response})
+	}))
+	defer server.Close()
+	client := orchestrator.NewClient(server.URL, nil)
+	before := orchestrator.ContainerInformation{ID: "website-id", Status: "running", StartedAt: "2026-01-01T10:00:00Z"}
+
+	// When
+	container, err := client.WaitForRestart(before, time.Second, time.Millisecond, nil)
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	i.Equal(container.StartedAt, "2026-01-01T10:05:00Z")
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/app/services"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// pullWithConflict adds local/blog, changes composer.json in the project and
+// upstream, and pulls the upstream change.
+func pullWithConflict(t *testing.T, dir string) error {
+	file := "readme.md"
+	touchFile(dir, file)
+	gitAdd(dir, file)
+	gitCommit(dir, file)
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	bare := filepath.Join(dir, "remotes", "blog.git")
+	initBarePackageRepository(t, bare, "v1.0.0")
+	remote, _ := services.GetPackageRemote("local/blog", "")
+	i := is.New(t)
+	i.NoErr(services.AddNewPackage("local/blog", remote, "main"))
+	_, err := services.RunCommand(fmt.Sprintf(`cd %s && echo '{"name": "local/project"}' > pkg/local/blog/composer.json && git commit -am "Change blog locally"`, dir))
+	i.NoErr(err)
+	upstream := t.TempDir()
+	_, err = services.RunCommand(fmt.Sprintf(`git clone %s %s && cd %s && echo '{"name": "local/upstream"}' > composer.json && git commit -am "Change upstream" && git push origin main`, bare, upstream, upstream))
+	i.NoErr(err)
+	return services.PullLatestChanges("local/blog", remote, "main")
+}
+
+func Test_pull_package_with_conflict_and_keep_theirs(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	err := pullWithConflict(t, dir)
+	// When
+	var conflict *services.PackageConflictError
+	i := is.New(t)
+	i.True(errors.As(err, &conflict))
+	i.Equal(conflict.Files, []string{"composer.json"})
+	i.True(services.MergeInProgress())
+	i.NoErr(services.ResolvePackageConflict("local/blog", "composer.json", true))
+	i.NoErr(services.ConcludePackageMerge("local/blog"))
+	// Then
+	content, _ := os.ReadFile(filepath.Join(dir, "pkg", "local", "blog", "composer.json"))
+	i.Equal(string(content), "{\"name\": \"local/upstream\"}\n")
+	i.True(!services.MergeInProgress())
+}
+
+func Test_pull_package_with_conflict_and_abort(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	err := pullWithConflict(t, dir)
+	i := is.New(t)
+	i.True(err != nil)
+	// When
+	err = services.AbortPackageMerge()
+	// Then
+	i.NoErr(err)
+	i.True(!services.MergeInProgress())
+	content, _ := os.ReadFile(filepath.Join(dir, "pkg", "local", "blog", "composer.json"))
+	i.Equal(string(content), "{\"name\": \"local/project\"}\n")
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"src/app/services"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_scaffold_package(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	// When
+	files, err := services.ScaffoldPackage("acme/blog-posts", "Blog posts for Confetti")
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	i.Equal(files, []string{"pkg/acme/blog-posts/composer.json", "pkg/acme/blog-posts/README.md", "pkg/acme/blog-posts/src/BlogPostsComponent.php"})
+	composer, _ := os.ReadFile(filepath.Join(dir, "pkg", "acme", "blog-posts", "composer.json"))
+	i.Equal(string(composer), `{
+    "name": "acme/blog-posts",
+    "description": "Blog posts for Confetti",
+    "type": "library",
+    "autoload": {
+        "psr-4": {
+            "Acme\\BlogPosts\\": "src/"
+        }
+    }
+}
+`)
+}
+
+func Test_create_and_push_package_to_local_repository(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	file := "readme.md"
+	touchFile(dir, file)
+	gitAdd(dir, file)
+	gitCommit(dir, file)
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	remote, _ := services.GetPackageRemote("local/blog", "")
+	_, err := services.ScaffoldPackage("local/blog", "")
+	i := is.New(t)
+	i.NoErr(err)
+	i.NoErr(services.StagePackage("local/blog"))
+	i.NoErr(services.CommitChanges("local/blog", "Create package local/blog"))
+	// When
+	err1 := services.InitUpstreamRepository(remote, services.DefaultPackageBranch)
+	err2 := services.PushPackage("local/blog", remote, services.DefaultPackageBranch)
+	version, err3 := services.ResolvePackageVersion(remote, "")
+	// Then
+	i.NoErr(err1)
+	i.NoErr(err2)
+	i.NoErr(err3)
+	i.True(version.Commit != "")
+	i.True(services.InitUpstreamRepository(remote, services.DefaultPackageBranch) != nil) // the branch exists now
+}
+
+func Test_invalid_package_name(t *testing.T) {
+	i := is.New(t)
+	i.True(services.ValidatePackageName("Acme/Blog") != nil)
+	i.True(services.ValidatePackageName("acme") != nil)
+	i.True(services.ValidatePackageName("..") != nil)
+	i.True(services.ValidatePackageName("acme/..") != nil)
+	i.NoErr(services.ValidatePackageName("confetti-cms/text"))
+}
@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/app/services"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_package_dependencies_from_composer_and_manifest(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	setPackageRequire(dir, "composer.json", "acme/blog", `{"php": "^8.2", "laravel/framework": "^11.0", "acme/text": "^1.2@dev", "mirror/image": "dev-main"}`)
+	setPackageRequire(dir, "confetti.json", "acme/blog", `{"confetti-cms/form": "*"}`)
+	i := is.New(t)
+	// When
+	dependencies, err := services.PackageDependencies("acme/blog")
+	// Then
+	i.NoErr(err)
+	i.Equal(dependencies, []services.PackageDependency{
+		{Package: "acme/text", Constraint: "^1.2", RequiredBy: "acme/blog"},
+		{Package: "confetti-cms/form", Constraint: "", RequiredBy: "acme/blog"},
+		{Package: "mirror/image", Constraint: "main", RequiredBy: "acme/blog"},
+	})
+}
+
+func Test_package_dependencies_with_invalid_name(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	setPackageRequire(dir, "confetti.json", "acme/blog", `{"acme/$(touch pwned)": "*"}`)
+	// When
+	_, err := services.PackageDependencies("acme/blog")
+	// Then
+	i := is.New(t)
+	i.Equal(err.Error(), `invalid dependency of acme/blog: "acme/$(touch pwned)" is not a valid package name, use vendor/name in lowercase, e.g. confetti-cms/text`)
+}
+
+func Test_walk_package_dependencies_with_cycle(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	setPackageRequire(dir, "composer.json", "acme/blog", `{"acme/text": "^1.0", "acme/image": "^2.0"}`)
+	setPackageRequire(dir, "composer.json", "acme/text", `{"acme/blog": "*", "acme/image": "^2.1"}`)
+	lock := services.PackageLock{Packages: map[string]services.LockedPackage{}}
+	lock.Packages["acme/text"] = services.LockedPackage{PackageVersion: services.PackageVersion{Constraint: "^1.0", Version: "v1.0.0", Ref: "v1.0.0"}}
+	i := is.New(t)
+	i.NoErr(lock.Write())
+	pulled := []string{}
+	pull := func(dependency services.PackageDependency) error {
+		pulled = append(pulled, dependency.Package+" "+dependency.Constraint)
+		setPackageRequire(dir, "composer.json", dependency.Package, `{}`)
+		return nil
+	}
+	// When
+	cycles, err := services.WalkPackageDependencies("acme/blog", map[string]bool{}, pull)
+	// Then
+	i.NoErr(err)
+	i.Equal(pulled, []string{"acme/image ^2.0 ^2.1"})
+	i.Equal(cycles, [][]string{{"acme/blog", "acme/text", "acme/blog"}})
+}
+
+func Test_walk_package_dependencies_with_conflicting_constraints(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	setPackageRequire(dir, "composer.json", "acme/blog", `{"acme/image": "^2.0", "acme/text": "^1.0"}`)
+	setPackageRequire(dir, "composer.json", "acme/text", `{"acme/image": "^3.0"}`)
+	i := is.New(t)
+	pull := func(dependency services.PackageDependency) error {
+		lock, _ := services.ReadPackageLock()
+		lock.Packages[dependency.Package] = services.LockedPackage{PackageVersion: services.PackageVersion{Constraint: dependency.Constraint, Version: "v2.0.0", Ref: "v2.0.0"}}
+		_ = os.MkdirAll(filepath.Join(dir, "pkg", dependency.Package), 0755)
+		return lock.Write()
+	}
+	// When
+	_, err := services.WalkPackageDependencies("acme/blog", map[string]bool{}, pull)
+	// Then
+	i.True(err != nil)
+	i.Equal(err.Error(), "acme/text requires acme/image ^3.0, but acme/image is already locked at v2.0.0")
+}
+
+func Test_walk_package_dependencies_with_constraints_of_installed_packages(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	setPackageRequire(dir, "composer.json", "acme/blog", `{"acme/image": "^2.1"}`)
+	setPackageRequire(dir, "composer.json", "acme/text", `{"acme/image": "~2.1.0"}`)
+	setPackageRequire(dir, "composer.json", "acme/image", `{}`)
+	lockDependencies(t, "v2.0.0")
+	pulled := []string{}
+	pull := pullFromTaggedRepository(t, &pulled)
+	// When
+	_, err := services.WalkPackageDependencies("acme/blog", map[string]bool{}, pull)
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	i.Equal(pulled, []string{"acme/image ^2.1 ~2.1.0 v2.1.5"})
+}
+
+func Test_walk_package_dependencies_without_version_for_all_installed_packages(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	setPackageRequire(dir, "composer.json", "acme/blog", `{"acme/image": "^3.0"}`)
+	setPackageRequire(dir, "composer.json", "acme/text", `{"acme/image": "^2.0"}`)
+	setPackageRequire(dir, "composer.json", "acme/image", `{}`)
+	lockDependencies(t, "v2.0.0")
+	pulled := []string{}
+	pull := pullFromTaggedRepository(t, &pulled)
+	// When
+	_, err := services.WalkPackageDependencies("acme/blog", map[string]bool{}, pull)
+	// Then
+	i := is.New(t)
+	i.True(err != nil)
+	i.True(strings.HasPrefix(err.Error(), "unable to pull acme/image (required by acme/blog ^3.0, acme/text ^2.0): no version of "))
+	i.Equal(len(pulled), 0)
+}
+
+func Test_combine_version_constraints(t *testing.T) {
+	cases := []struct {
+		constraints []string
+		combined    string
+		ok          bool
+	}{
+		{[]string{"^2.0", ""}, "^2.0", true},
+		{[]string{"^1.0 || ^2.0", ">=1.5"}, "^1.0 >=1.5 || ^2.0 >=1.5", true},
+		{[]string{"main", "main", ""}, "main", true},
+		{[]string{"main", "^2.0"}, "", false},
+		{[]string{"^2.0", "develop"}, "", false},
+	}
+	for _, c := range cases {
+		// When
+		combined, ok := services.CombineVersionConstraints(c.constraints...)
+		// Then
+		i := is.New(t)
+		i.Equal(combined, c.combined)
+		i.Equal(ok, c.ok)
+	}
+}
+
+// lockDependencies locks acme/text at v1.0.0 and acme/image at the version.
+func lockDependencies(t *testing.T, image string) {
+	lock := services.PackageLock{Packages: map[string]services.LockedPackage{}}
+	lock.Packages["acme/text"] = services.LockedPackage{PackageVersion: services.PackageVersion{Constraint: "^1.0", Version: "v1.0.0", Ref: "v1.0.0"}}
+	lock.Packages["acme/image"] = services.LockedPackage{PackageVersion: services.PackageVersion{Constraint: "^2.0", Version: image, Ref: image}}
+	is.New(t).NoErr(lock.Write())
+}
+
+// pullFromTaggedRepository resolves the version in a repository with the tags
+// v2.0.0, v2.1.5, v2.2.0 and v3.0.0 and locks it.
+func pullFromTaggedRepository(t *testing.T, pulled *[]string) func(services.PackageDependency) error {
+	upstream := filepath.Join(t.TempDir(), "image.git")
+	initBarePackageRepository(t, upstream, "v2.0.0")
+	_, err := services.RunCommand(fmt.Sprintf("cd %s && git tag v2.1.5 && git tag v2.2.0 && git tag v3.0.0", upstream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(dependency services.PackageDependency) error {
+		version, err := services.ResolvePackageVersion(services.PackageRemote{Url: upstream}, dependency.Constraint)
+		if err != nil {
+			return err
+		}
+		*pulled = append(*pulled, dependency.Package+" "+dependency.Constraint+" "+version.Version)
+		lock, _ := services.ReadPackageLock()
+		lock.Packages[dependency.Package] = services.LockedPackage{PackageVersion: version}
+		return lock.Write()
+	}
+}
+
+func setPackageRequire(dir, file, pkg, require string) {
+	pkgDir := filepath.Join(dir, "pkg", pkg)
+	_ = os.MkdirAll(pkgDir, 0755)
+	_ = os.WriteFile(filepath.Join(pkgDir, file), []byte(`{"name": "`+pkg+`", "require": `+require+`}`), 0644)
+}
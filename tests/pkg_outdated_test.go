@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/app/services"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_package_outdated(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	file := "readme.md"
+	touchFile(dir, file)
+	gitAdd(dir, file)
+	gitCommit(dir, file)
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	bare := filepath.Join(dir, "remotes", "blog.git")
+	initBarePackageRepository(t, bare, "v1.0.0")
+	remote, _ := services.GetPackageRemote("local/blog", "")
+	i := is.New(t)
+	version, err := services.ResolvePackageVersion(remote, "^1.0")
+	i.NoErr(err)
+	i.NoErr(services.AddNewPackage("local/blog", remote, version.Ref))
+	lock, _ := services.ReadPackageLock()
+	lock.Packages["local/blog"] = services.LockedPackage{PackageVersion: version, Source: remote.Url}
+	i.NoErr(lock.Write())
+	upstream := t.TempDir()
+	_, err = services.RunCommand(fmt.Sprintf(`git clone %s %s && cd %s && touch new.php && git add . && git commit -m "Add upstream file" && git tag v1.1.0 && git tag v2.0.0 && git push origin main --tags`, bare, upstream, upstream))
+	i.NoErr(err)
+	// When
+	outdated, err := services.CheckPackageOutdated("local/blog")
+	// Then
+	i.NoErr(err)
+	i.Equal(outdated.Current, "v1.0.0")
+	i.Equal(outdated.Wanted, "v1.1.0")
+	i.Equal(outdated.Latest, "v2.0.0")
+	i.Equal(outdated.VersionsBehind, 2)
+	i.Equal(outdated.CommitsBehind, 1)
+}
+
+func Test_pull_older_version_than_in_pkg(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	file := "readme.md"
+	touchFile(dir, file)
+	gitAdd(dir, file)
+	gitCommit(dir, file)
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	bare := filepath.Join(dir, "remotes", "blog.git")
+	initBarePackageRepository(t, bare, "v1.0.0")
+	remote, _ := services.GetPackageRemote("local/blog", "")
+	i := is.New(t)
+	upstream := t.TempDir()
+	_, err := services.RunCommand(fmt.Sprintf(`git clone %s %s && cd %s && touch new.php && git add . && git commit -m "Add upstream file" && git tag v1.1.0 && git push origin main --tags`, bare, upstream, upstream))
+	i.NoErr(err)
+	i.NoErr(services.AddNewPackage("local/blog", remote, "v1.1.0"))
+	// When
+	err1 := services.PullLatestChanges("local/blog", remote, "v1.0.0")
+	err2 := services.PullLatestChanges("local/blog", remote, "v1.1.0")
+	// Then
+	i.Equal(err1.Error(), "v1.0.0 is older than the version of package local/blog in pkg/, remove the package first (conf pkg:remove -p local/blog) to pull an older version")
+	i.NoErr(err2)
+}
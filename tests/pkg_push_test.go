@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"src/app/services"
+	"src/config"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_push_package_with_local_commit(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	file := "readme.md"
+	touchFile(dir, file)
+	gitAdd(dir, file)
+	gitCommit(dir, file)
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	initBarePackageRepository(t, filepath.Join(dir, "remotes", "blog.git"), "v1.0.0")
+	remote, _ := services.GetPackageRemote("local/blog", "")
+	i := is.New(t)
+	i.NoErr(services.AddNewPackage("local/blog", remote, "main"))
+	setFileContent(dir, "pkg/local/blog/composer.json", "\n")
+	changes, err := services.PackageUncommittedChanges("local/blog")
+	i.NoErr(err)
+	i.Equal(len(changes), 1)
+	_, err = services.RunCommand(fmt.Sprintf(`cd %s && git commit -am "Change blog locally"`, dir))
+	i.NoErr(err)
+	// When
+	plan, err1 := services.PlanPackagePush("local/blog", remote, "main", false)
+	err2 := services.ExecutePackagePush(remote, plan, false)
+	again, err3 := services.PlanPackagePush("local/blog", remote, "main", false)
+	// Then
+	i.NoErr(err1)
+	i.NoErr(err2)
+	i.NoErr(err3)
+	i.Equal(len(plan.Commits), 1)
+	i.True(plan.FastForward)
+	i.Equal(len(again.Commits), 0)
+	i.Equal(again.SplitCommit, plan.SplitCommit)
+}
+
+func Test_push_package_when_upstream_has_new_commits(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	file := "readme.md"
+	touchFile(dir, file)
+	gitAdd(dir, file)
+	gitCommit(dir, file)
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	bare := filepath.Join(dir, "remotes", "blog.git")
+	initBarePackageRepository(t, bare, "v1.0.0")
+	remote, _ := services.GetPackageRemote("local/blog", "")
+	i := is.New(t)
+	i.NoErr(services.AddNewPackage("local/blog", remote, "main"))
+	setFileContent(dir, "pkg/local/blog/composer.json", "\n")
+	_, err := services.RunCommand(fmt.Sprintf(`cd %s && git commit -am "Change blog locally"`, dir))
+	i.NoErr(err)
+	upstream := t.TempDir()
+	_, err = services.RunCommand(fmt.Sprintf(`git clone %s %s && cd %s && touch new.php && git add . && git commit -m "Add upstream file" && git push origin main`, bare, upstream, upstream))
+	i.NoErr(err)
+	// When
+	plan, err1 := services.PlanPackagePush("local/blog", remote, "main", false)
+	err2 := services.ExecutePackagePush(remote, plan, false)
+	// Then
+	i.NoErr(err1)
+	i.True(!plan.FastForward)
+	i.True(err2 != nil)
+}
+
+func Test_push_package_in_directory_with_space(t *testing.T) {
+	// Given
+	dir := filepath.Join(t.TempDir(), "my project")
+	_ = os.MkdirAll(dir, 0755)
+	previous := config.Path.Root
+	config.Path.Root = dir + string(os.PathSeparator)
+	t.Cleanup(func() { config.Path.Root = previous })
+	i := is.New(t)
+	for _, args := range [][]string{{"init", "-b", "main"}, {"commit", "--allow-empty", "-m", "first commit"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		i.NoErr(cmd.Run())
+	}
+	bare := filepath.Join(t.TempDir(), "blog.git")
+	initBarePackageRepository(t, bare, "v1.0.0")
+	remote := services.PackageRemote{Url: bare}
+	i.NoErr(services.AddNewPackage("local/blog", remote, "main"))
+	setFileContent(dir, "pkg/local/blog/composer.json", "\n")
+	changes, err := services.PackageUncommittedChanges("local/blog")
+	i.NoErr(err)
+	i.Equal(len(changes), 1)
+	i.NoErr(services.StagePackage("local/blog"))
+	cmd := exec.Command("git", "commit", "-m", "Change blog locally")
+	cmd.Dir = dir
+	i.NoErr(cmd.Run())
+	// When
+	plan, err1 := services.PlanPackagePush("local/blog", remote, "main", false)
+	err2 := services.ExecutePackagePush(remote, plan, false)
+	// Then
+	i.NoErr(err1)
+	i.NoErr(err2)
+	i.Equal(len(plan.Commits), 1)
+}
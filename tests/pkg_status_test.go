@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/app/services"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_package_status_after_local_and_upstream_commits(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	file := "readme.md"
+	touchFile(dir, file)
+	gitAdd(dir, file)
+	gitCommit(dir, file)
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	bare := filepath.Join(dir, "remotes", "blog.git")
+	initBarePackageRepository(t, bare, "v1.0.0")
+	remote, _ := services.GetPackageRemote("local/blog", "")
+	i := is.New(t)
+	i.NoErr(services.AddNewPackage("local/blog", remote, "main"))
+	setFileContent(dir, "pkg/local/blog/composer.json", "\n")
+	_, err := services.RunCommand(fmt.Sprintf(`cd %s && git commit -am "Change blog locally"`, dir))
+	i.NoErr(err)
+	upstream := t.TempDir()
+	_, err = services.RunCommand(fmt.Sprintf(`git clone %s %s && cd %s && touch new.php && git add . && git commit -m "Add upstream file" && git push origin main`, bare, upstream, upstream))
+	i.NoErr(err)
+	// When
+	packages, err1 := services.ListPackages()
+	status, err2 := services.GetPackageStatus("local/blog", true)
+	// Then
+	i.NoErr(err1)
+	i.NoErr(err2)
+	i.Equal(packages, []string{"local/blog"})
+	i.Equal(status.Source, bare)
+	i.True(status.PulledCommit != "")
+	i.Equal(len(status.LocalCommits), 1)
+	i.Equal(len(status.UpstreamCommits), 1)
+}
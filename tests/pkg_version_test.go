@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"src/app/services"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_version_constraints(t *testing.T) {
+	cases := []struct {
+		constraint string
+		version    string
+		matches    bool
+	}{
+		{"^1.2", "v1.2.0", true},
+		{"^1.2", "v1.9.3", true},
+		{"^1.2", "v2.0.0", false},
+		{"^1.2", "v1.1.9", false},
+		{"^0.3", "v0.3.5", true},
+		{"^0.3", "v0.4.0", false},
+		{"~1.2.3", "v1.2.9", true},
+		{"~1.2.3", "v1.3.0", false},
+		{"~1.2", "v1.9.0", true},
+		{"1.x", "v1.5.0", true},
+		{"1.x", "v2.0.0", false},
+		{">=1.0 <2.0", "v1.5.0", true},
+		{">=1.0 <2.0", "v2.0.0", false},
+		{"^1.0 || ^3.0", "v3.1.0", true},
+		{"v1.2.3", "1.2.3", true},
+		{"*", "v4.0.0", true},
+		{"^1.2", "v1.3.0-beta", false},
+		{"1.3.0-beta", "v1.3.0-beta", true},
+	}
+	for _, c := range cases {
+		// Given
+		constraint, err := services.ParseVersionConstraint(c.constraint)
+		version, ok := services.ParseSemVer(c.version)
+		// When
+		matches := constraint.Matches(version)
+		// Then
+		i := is.New(t)
+		i.NoErr(err)
+		i.True(ok)
+		if matches != c.matches {
+			t.Errorf("%s matches %s: expected %v, got %v", c.constraint, c.version, c.matches, matches)
+		}
+	}
+}
+
+func Test_compare_prerelease_versions(t *testing.T) {
+	// Given
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.10", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0"}
+	for n := 1; n < len(ordered); n++ {
+		lower, _ := services.ParseSemVer(ordered[n-1])
+		higher, _ := services.ParseSemVer(ordered[n])
+		// When
+		compared := lower.Compare(higher)
+		reversed := higher.Compare(lower)
+		// Then
+		if compared != -1 || reversed != 1 {
+			t.Errorf("expected %s < %s, got %d and %d", ordered[n-1], ordered[n], compared, reversed)
+		}
+	}
+}
+
+func Test_resolve_package_version(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	file := "readme.md"
+	touchFile(dir, file)
+	gitAdd(dir, file)
+	gitCommit(dir, file)
+	gitTag(dir, "v1.0.0", "v1.2.0", "v1.3.0-beta", "v2.0.0", "latest")
+	remote := services.PackageRemote{Url: dir}
+	// When
+	constraint, err1 := services.ResolvePackageVersion(remote, "^1.0")
+	tag, err2 := services.ResolvePackageVersion(remote, "latest")
+	_, err3 := services.ResolvePackageVersion(remote, "^3.0")
+	// Then
+	i := is.New(t)
+	i.NoErr(err1)
+	i.Equal(constraint.Version, "v1.2.0")
+	i.Equal(constraint.Ref, "v1.2.0")
+	i.Equal(constraint.Constraint, "^1.0")
+	i.Equal(constraint.Commit, getCommitFromLog(dir, 0))
+	i.NoErr(err2)
+	i.Equal(tag.Ref, "latest")
+	i.Equal(err3.Error(), "no version of "+dir+" matches ^3.0, available versions are v1.0.0, v1.2.0, v1.3.0-beta, v2.0.0")
+}
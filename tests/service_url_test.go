@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"src/app/services"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_service_url_by_default_container(t *testing.T) {
+	// Given
+	env := services.Environment{
+		Name: "dev",
+		Containers: []services.ContainerConfig{
+			{Hosts: []string{"example.com"}, Paths: []string{"/__ENV__/__SERVICE__", "/api/__SERVICE__"}},
+		},
+	}
+
+	// When
+	url, err := env.GetServiceUrl("confetti-cms/parser")
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	i.Equal(url, "https://example.com/dev/confetti-cms/parser")
+}
+
+func Test_service_url_through_the_second_path(t *testing.T) {
+	// Given
+	env := services.Environment{
+		Name: "dev",
+		Containers: []services.ContainerConfig{
+			{Hosts: []string{"example.com"}, Paths: []string{"/__ENV__/confetti-cms/auth", "/api/__SERVICE__"}},
+		},
+	}
+
+	// When
+	parser, err1 := env.GetServiceUrl("confetti-cms/parser")
+	auth, err2 := env.GetServiceUrl("confetti-cms/auth")
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err1)
+	i.NoErr(err2)
+	i.Equal(parser, "https://example.com/api/confetti-cms/parser")
+	i.Equal(auth, "https://example.com/dev/confetti-cms/auth")
+}
+
+func Test_service_url_through_a_path_prefix(t *testing.T) {
+	// Given
+	env := services.Environment{
+		Name: "dev",
+		Containers: []services.ContainerConfig{
+			{Hosts: []string{"example.com"}, Paths: []string{"/services/", "/legacy"}},
+		},
+	}
+
+	// When
+	url, err := env.GetServiceUrl("confetti-cms/parser")
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	i.Equal(url, "https://example.com/services")
+}
+
+func Test_service_url_with_protocol_and_port(t *testing.T) {
+	// Given
+	env := services.Environment{
+		Name: "dev",
+		Containers: []services.ContainerConfig{
+			{Hosts: []string{"example.com"}},
+			{Name: "confetti-cms/parser", Hosts: []string{"parser.localhost"}, Protocol: "http", Port: 8080},
+		},
+	}
+
+	// When
+	url, err := env.GetServiceUrl("confetti-cms/parser")
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	i.Equal(url, "http://parser.localhost:8080")
+}
+
+func Test_service_url_without_default_container(t *testing.T) {
+	// Given
+	env := services.Environment{
+		Name: "dev",
+		Containers: []services.ContainerConfig{
+			{Name: "confetti-cms/auth", Hosts: []string{"auth.example.com"}},
+		},
+	}
+
+	// When
+	_, err := env.GetServiceUrl("confetti-cms/parser")
+
+	// Then
+	i := is.New(t)
+	i.True(err != nil)
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/app/services"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_pull_package_and_push_on_top_of_upstream(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	file := "readme.md"
+	touchFile(dir, file)
+	gitAdd(dir, file)
+	gitCommit(dir, file)
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	bare := filepath.Join(dir, "remotes", "blog.git")
+	initBarePackageRepository(t, bare, "v1.0.0")
+	remote, _ := services.GetPackageRemote("local/blog", "")
+	i := is.New(t)
+	i.NoErr(services.AddNewPackage("local/blog", remote, "main"))
+	upstream := t.TempDir()
+	_, err := services.RunCommand(fmt.Sprintf(`git clone %s %s && cd %s && touch new.php && git add . && git commit -m "Add upstream file" && git push origin main`, bare, upstream, upstream))
+	i.NoErr(err)
+	upstreamHead, _ := services.RunCommand(fmt.Sprintf("cd %s && git rev-parse HEAD", upstream))
+	// When
+	err1 := services.PullLatestChanges("local/blog", remote, "main")
+	head, _ := services.RunCommand(fmt.Sprintf("cd %s && git rev-parse HEAD", dir))
+	err2 := services.PullLatestChanges("local/blog", remote, "main")
+	again, _ := services.RunCommand(fmt.Sprintf("cd %s && git rev-parse HEAD", dir))
+	_, split, err3 := services.LastSubtreeMerge("local/blog")
+	setFileContent(dir, "pkg/local/blog/new.php", "<?php\n")
+	_, err = services.RunCommand(fmt.Sprintf(`cd %s && git commit -am "Change blog locally"`, dir))
+	i.NoErr(err)
+	plan, err4 := services.PlanPackagePush("local/blog", remote, "main", false)
+	// Then
+	i.NoErr(err1)
+	i.NoErr(err2)
+	i.NoErr(err3)
+	i.NoErr(err4)
+	_, err = os.Stat(filepath.Join(dir, "pkg", "local", "blog", "new.php"))
+	i.NoErr(err)
+	i.Equal(head, again)
+	i.Equal(split, strings.TrimSpace(upstreamHead))
+	i.True(plan.FastForward)
+	i.Equal(plan.Commits, []string{plan.SplitCommit[:7] + " Change blog locally"})
+}
+
+func Test_split_package_is_the_same_after_rejoin(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	file := "readme.md"
+	touchFile(dir, file)
+	gitAdd(dir, file)
+	gitCommit(dir, file)
+	_ = os.WriteFile(filepath.Join(dir, "config.json5"), []byte(packageSourcesConfig), 0644)
+	initBarePackageRepository(t, filepath.Join(dir, "remotes", "blog.git"), "v1.0.0")
+	remote, _ := services.GetPackageRemote("local/blog", "")
+	i := is.New(t)
+	i.NoErr(services.AddNewPackage("local/blog", remote, "main"))
+	setFileContent(dir, "pkg/local/blog/composer.json", "\n")
+	_, err := services.RunCommand(fmt.Sprintf(`cd %s && git commit -am "Change blog locally"`, dir))
+	i.NoErr(err)
+	// When
+	split, err1 := services.SplitPackage("local/blog", false)
+	rejoined, err2 := services.SplitPackage("local/blog", true)
+	setFileContent(dir, "pkg/local/blog/composer.json", "{}\n")
+	_, err = services.RunCommand(fmt.Sprintf(`cd %s && git commit -am "Change blog again"`, dir))
+	i.NoErr(err)
+	next, err3 := services.SplitPackage("local/blog", false)
+	parent, _ := services.RunCommand(fmt.Sprintf("cd %s && git rev-parse %s^", dir, next))
+	// Then
+	i.NoErr(err1)
+	i.NoErr(err2)
+	i.NoErr(err3)
+	i.Equal(rejoined, split)
+	i.Equal(strings.TrimSpace(parent), split)
+}
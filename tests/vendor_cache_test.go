@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"src/app/services"
+	"src/config"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_vendor_cache_without_composer_lock(t *testing.T) {
+	// Given
+	initTestGit()
+
+	// When
+	key, err := services.VendorCacheKey()
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	i.Equal(key, "")
+}
+
+func Test_vendor_cache_store_and_restore(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	config.Cache.Dir = filepath.Join(dir, "cache")
+	touchFile(dir, "composer.lock")
+	setFileContent(dir, "composer.lock", "{}")
+	touchFile(dir, "vendor/autoload.php")
+	setFileContent(dir, "vendor/autoload.php", "<?php")
+	key, _ := services.VendorCacheKey()
+	i := is.New(t)
+	i.NoErr(services.StoreVendorInCache(key))
+	deleteFile(dir, "vendor/autoload.php")
+
+	// When
+	restored, err := services.RestoreVendorFromCache(key)
+
+	// Then
+	i.NoErr(err)
+	i.True(restored)
+	content, _ := os.ReadFile(filepath.Join(dir, "vendor", "autoload.php"))
+	i.Equal(string(content), "<?php")
+}
+
+func Test_vendor_cache_prune(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	config.Cache.Dir = filepath.Join(dir, "cache")
+	touchFile(dir, "cache/vendor/a.zip")
+	setFileContent(dir, "cache/vendor/a.zip", "content")
+
+	// When
+	removed, err := services.PruneCache(0)
+
+	// Then
+	i := is.New(t)
+	i.NoErr(err)
+	i.Equal(len(removed), 1)
+	entries, _ := services.GetCacheEntries()
+	i.Equal(len(entries), 0)
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"src/app/services"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func Test_vendor_not_stale_after_install(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	touchFile(dir, "composer.lock")
+	touchFile(dir, "vendor/autoload.php")
+	i := is.New(t)
+	i.NoErr(services.RecordVendorLockHash())
+
+	// When
+	stale, err := services.VendorIsStale()
+
+	// Then
+	i.NoErr(err)
+	i.True(!stale)
+}
+
+func Test_vendor_stale_when_composer_lock_changed(t *testing.T) {
+	// Given
+	dir := initTestGit()
+	touchFile(dir, "composer.lock")
+	touchFile(dir, "vendor/autoload.php")
+	i := is.New(t)
+	i.NoErr(services.RecordVendorLockHash())
+	setFileContent(dir, "composer.lock", `{"packages": []}`)
+
+	// When
+	stale, err := services.VendorIsStale()
+
+	// Then
+	i.NoErr(err)
+	i.True(stale)
+}